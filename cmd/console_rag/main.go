@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"console_rag/internal/app"
@@ -16,12 +17,19 @@ import (
 )
 
 func main() {
+	// Подкоманда chunk: пробное разбиение без индексации
+	if len(os.Args) > 1 && os.Args[1] == "chunk" {
+		runChunkCommand(os.Args[2:])
+		return
+	}
+
 	// Парсим флаги командной строки
-	referenceDoc := flag.String("reference-doc", "", "Path to reference document (required)")
+	referenceDoc := flag.String("reference-doc", "", "Path to reference document, directory or glob pattern like 'laws/*.md' (required)")
 	dataDir := flag.String("data", "./data", "Data directory for vector DB")
 	checkDoc := flag.String("check-doc", "", "Path to check document (optional)")
 	outputFile := flag.String("output", "", "Save analysis results to file (optional)")
 	runChunker := flag.Bool("run-chunker", true, "Run chunker to process the reference document (optional)")
+	encoding := flag.String("encoding", "", "Encoding of .txt/.md files: auto, utf-8, windows-1251, koi8-r, ibm866, utf-16le, utf-16be (default: TEXT_ENCODING from .env)")
 	flag.Parse()
 
 	//	*referenceDoc = "../../docs/LaborCodexRus.md"
@@ -31,7 +39,8 @@ func main() {
 		log.Fatal("Error: --reference-doc flag is required\nUsage: console_rag --reference-doc=/path/to/document.md")
 	}
 
-	if _, err := os.Stat(*referenceDoc); os.IsNotExist(err) {
+	// Шаблон ("laws/*.md") раскрывается при индексации
+	if _, err := os.Stat(*referenceDoc); os.IsNotExist(err) && !strings.ContainsAny(*referenceDoc, "*?[") {
 		log.Fatalf("Error: reference document not found: %s", *referenceDoc)
 	}
 
@@ -39,6 +48,9 @@ func main() {
 	os.Setenv("DATA_DIR", *dataDir)
 	os.Setenv("CHECK_DOC", *checkDoc)
 	os.Setenv("RUN_CHUNKER", strconv.FormatBool(*runChunker))
+	if *encoding != "" {
+		os.Setenv("TEXT_ENCODING", *encoding)
+	}
 
 	_ = godotenv.Load()
 	cfg := config.Config{}
@@ -81,3 +93,38 @@ func main() {
 		log.Fatalf("app stopped with error: %v", err)
 	}
 }
+
+// runChunkCommand разбивает файл на чанки и выводит их в JSONL со статистикой, не обращаясь к embedding и LLM
+// Usage: console_rag chunk --file=/path/to/document.md [--method=legal] [--out=chunks.jsonl]
+func runChunkCommand(args []string) {
+	fs := flag.NewFlagSet("chunk", flag.ExitOnError)
+	file := fs.String("file", "", "Path to document to chunk (required)")
+	method := fs.String("method", "", "Chunking method (default: CHUNK_METHOD from .env)")
+	outFile := fs.String("out", "", "Write chunks as JSONL to file instead of stdout (optional)")
+	checkSide := fs.Bool("check", false, "Normalize as a check document (NORMALIZE_CHECK) instead of reference")
+	encoding := fs.String("encoding", "", "Encoding of .txt/.md files (default: TEXT_ENCODING from .env)")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		*file = fs.Arg(0)
+	}
+	if *file == "" {
+		log.Fatal("Error: --file flag is required\nUsage: console_rag chunk --file=/path/to/document.md [--method=legal] [--out=chunks.jsonl]")
+	}
+
+	_ = godotenv.Load()
+	cfg := config.Config{}
+	if err := config.InitChunking(&cfg); err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	if *method != "" {
+		cfg.ChunkMethod = *method
+	}
+	if *encoding != "" {
+		cfg.TextEncoding = *encoding
+	}
+
+	if err := app.DumpChunks(&cfg, *file, *outFile, *checkSide); err != nil {
+		log.Fatalf("chunking failed: %v", err)
+	}
+}
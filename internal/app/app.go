@@ -12,22 +12,27 @@ import (
 
 	"console_rag/internal/chunker"
 	"console_rag/internal/config"
+	"console_rag/internal/normalize"
 
-	"github.com/ledongthuc/pdf"
 	"github.com/philippgille/chromem-go"
 	"google.golang.org/genai"
 )
 
 type App struct {
-	cfg            *config.Config
-	db             *chromem.DB
-	metadata       *Metadata
-	fileDB         string
-	fileMetadata   string
-	embeddingFunc  chromem.EmbeddingFunc
-	chunkerFactory *chunker.Factory
-	outputPath     string
-	logger         Logger
+	cfg                *config.Config
+	db                 *chromem.DB
+	metadata           *Metadata
+	fileDB             string
+	fileMetadata       string
+	embeddingFunc      chromem.EmbeddingFunc
+	chunkerFactory     *chunker.Factory // для эталонного документа
+	chunkerConfig      chunker.Config
+	checkFactory       *chunker.Factory    // для проверяемых документов: своя нормализация
+	checkNormalize     *normalize.Pipeline // нормализация проверяемого текста
+	referenceNormalize *normalize.Pipeline // нормализация эталона — записывается в манифест индекса
+	readers            *readerRegistry     // чтение документов по формату
+	outputPath         string
+	logger             Logger
 
 	httpClient   *http.Client
 	geminiClient *genai.Client
@@ -36,37 +41,69 @@ type App struct {
 type Metadata struct {
 	Files    map[string]FileInfo `json:"files"`
 	DataPath string              `json:"data_path"`
+	Manifest *Manifest           `json:"manifest,omitempty"`
 }
 
 type FileInfo struct {
 	Path         string    `json:"path"`
 	LastModified time.Time `json:"last_modified"`
 	Size         int64     `json:"size"`
+	ContentHash  string    `json:"content_hash,omitempty"` // sha256 содержимого файла
+	// ID чанков файла в коллекции docs (в режиме parent–child — дочерних) и в коллекции parents:
+	// по ним при переиндексации удаляются исчезнувшие чанки и переиспользуются embedding'и
+	Chunks  []string `json:"chunks,omitempty"`
+	Parents []string `json:"parents,omitempty"`
+	// Поля YAML front matter документа (title, edition, jurisdiction ...)
+	FrontMatter map[string]string `json:"front_matter,omitempty"`
 }
 
 func New(cfg *config.Config) (*App, error) {
-	// Создаём фабрику chunker'ов
-	chunkerConfig := chunker.Config{
-		MaxChunkSize: cfg.ChunkSize,
-		Overlap:      cfg.ChunkOverlap,
-	}
+	normalized := true
+	embeddingFunc := chromem.NewEmbeddingFuncOpenAICompat(cfg.LlmEmbed.URL, cfg.LlmEmbed.Key, cfg.LlmEmbed.Model, &normalized)
 
 	app := &App{
-		cfg:            cfg,
-		metadata:       &Metadata{Files: make(map[string]FileInfo)},
-		chunkerFactory: chunker.NewFactory(chunkerConfig),
-		logger:         &ConsoleLogger{},
+		cfg:      cfg,
+		metadata: &Metadata{Files: make(map[string]FileInfo)},
+		logger:   &ConsoleLogger{},
+	}
+	app.embeddingFunc = app.checkedEmbedding(embeddingFunc)
+
+	var err error
+	if app.readers, err = app.newReaders(); err != nil {
+		return nil, err
+	}
+
+	// Создаём фабрики chunker'ов: эталон и проверяемые документы нормализуются по-разному
+	refNormalize, err := normalize.New(cfg.NormalizeReference, app.logger.Infof, app.logger.Debugf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NORMALIZE_REFERENCE: %w", err)
+	}
+	app.checkNormalize, err = normalize.New(cfg.NormalizeCheck, app.logger.Infof, app.logger.Debugf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NORMALIZE_CHECK: %w", err)
+	}
+
+	app.referenceNormalize = refNormalize
+
+	app.chunkerConfig, err = newChunkerConfig(cfg, chunker.EmbeddingFunc(app.embeddingFunc), refNormalize)
+	if err != nil {
+		return nil, err
 	}
+	app.chunkerFactory = chunker.NewFactory(app.chunkerConfig)
+
+	checkConfig := app.chunkerConfig
+	checkConfig.Normalize = app.checkNormalize.Apply
+	app.checkFactory = chunker.NewFactory(checkConfig)
 
-	docBaseName := strings.TrimSuffix(filepath.Base(cfg.ReferenceDoc), filepath.Ext(cfg.ReferenceDoc))
+	app.logger.Infof("🧽 Normalization: reference=%v, check=%v", refNormalize.Names(), app.checkNormalize.Names())
+
+	// Hash абсолютного пути: одноимённые эталоны из разных директорий не затирают индексы друг друга
+	docBaseName := corpusName(cfg.ReferenceDoc) + "_" + sourceHash(cfg.ReferenceDoc)
 	app.fileMetadata = filepath.Join(cfg.DataDir, docBaseName+"_metadata.json")
 	app.fileDB = filepath.Join(cfg.DataDir, docBaseName+".gob")
 	app.logger.Infof("DB file: %s", app.fileDB)
 	app.logger.Infof("Metadata file: %s", app.fileMetadata)
 
-	normalized := true
-	app.embeddingFunc = chromem.NewEmbeddingFuncOpenAICompat(cfg.LlmEmbed.URL, cfg.LlmEmbed.Key, cfg.LlmEmbed.Model, &normalized)
-
 	app.db = chromem.NewDB()
 
 	app.httpClient = &http.Client{
@@ -93,15 +130,52 @@ func New(cfg *config.Config) (*App, error) {
 	return app, nil
 }
 
+// newChunkerConfig собирает параметры chunker'ов из конфигурации приложения.
+// embed может быть nil — тогда semantic chunker недоступен
+func newChunkerConfig(cfg *config.Config, embed chunker.EmbeddingFunc, norm *normalize.Pipeline) (chunker.Config, error) {
+	sizeUnit, err := chunker.ParseSizeUnit(cfg.ChunkUnit)
+	if err != nil {
+		return chunker.Config{}, fmt.Errorf("invalid CHUNK_SIZE_UNIT: %w", err)
+	}
+
+	switch cfg.MarkdownStrategy {
+	case chunker.HeadingStrategyFirst, chunker.HeadingStrategyAuto:
+	default:
+		return chunker.Config{}, fmt.Errorf("invalid MD_STRATEGY: %s (available: %s, %s)",
+			cfg.MarkdownStrategy, chunker.HeadingStrategyFirst, chunker.HeadingStrategyAuto)
+	}
+
+	return chunker.Config{
+		MaxChunkSize:       cfg.ChunkSize,
+		Overlap:            cfg.ChunkOverlap,
+		MinChunkSize:       cfg.ChunkMinSize,
+		SizeUnit:           sizeUnit,
+		PrependHeadingPath: cfg.ChunkPath,
+		Fallback:           cfg.ChunkFallback,
+		HeadingMinLevel:    cfg.MarkdownMinLevel,
+		HeadingMaxLevel:    cfg.MarkdownMaxLevel,
+		MinHeadings:        cfg.MarkdownMinHeadings,
+		HeadingStrategy:    cfg.MarkdownStrategy,
+		ExtractAmendments:  cfg.ExtractAmendments,
+		Normalize:          norm.Apply,
+		Embed:              embed,
+		EmbedConcurrency:   cfg.MaxConcurrency,
+		SemanticThreshold:  cfg.SemanticThreshold,
+	}, nil
+}
+
 func (a *App) Init(ctx context.Context) error {
 	if err := a.validateLLMConfig(); err != nil {
 		return fmt.Errorf("invalid LLM configuration: %w", err)
 	}
 
-	fileInfo, err := os.Stat(a.cfg.ReferenceDoc)
+	files, err := a.referenceFiles(a.cfg.ReferenceDoc)
 	if err != nil {
-		return fmt.Errorf("reference document not found: %w", err)
+		return err
 	}
+	a.logger.Infof("📚 Reference corpus: %d document(s)", len(files))
+
+	manifest := a.currentManifest()
 
 	// Check if DB exists for this document
 	if _, err := os.Stat(a.fileDB); err == nil {
@@ -111,15 +185,49 @@ func (a *App) Init(ctx context.Context) error {
 		}
 		if err := a.loadMetadata(); err != nil {
 			a.logger.Errorf("Warning: failed to load metadata: %v", err)
+			a.metadata = nil
+		}
+		// Повреждённые метаданные или "null" — индекс без манифеста: diff потребует перестроить его
+		if a.metadata == nil {
+			a.metadata = &Metadata{}
+		}
+		if a.metadata.Files == nil {
+			a.metadata.Files = make(map[string]FileInfo)
 		}
 		a.logger.Infof("✅ Database loaded")
+		if a.metadata.Manifest != nil {
+			manifest.Dimension = a.metadata.Manifest.Dimension
+		}
 
-		return nil
+		// Индекс с другой embedding-моделью или другим разбиением несовместим с текущими настройками
+		if diffs := a.metadata.Manifest.diff(manifest); len(diffs) > 0 {
+			if !a.cfg.IndexRebuild {
+				return fmt.Errorf("index %s was built with different settings (%s): restore them, delete the index or set INDEX_REBUILD=true",
+					a.fileDB, strings.Join(diffs, "; "))
+			}
+			for _, d := range diffs {
+				a.logger.Infof("⚠️  Index settings changed: %s", d)
+			}
+			a.logger.Infof("🔁 Rebuilding index from scratch...")
+			a.db = chromem.NewDB()
+			a.metadata = &Metadata{Files: make(map[string]FileInfo)}
+			manifest.Dimension = 0
+		}
+	} else {
+		a.logger.Infof("📚 No DB found, indexing documents...")
 	}
 
-	a.logger.Infof("📚 No DB found, indexing document...")
-	if err := a.indexDocument(ctx, fileInfo); err != nil {
-		return fmt.Errorf("failed to index document: %w", err)
+	// Embedding API опрашивается только для нового (или пустого) индекса
+	if manifest.Dimension == 0 {
+		if manifest.Dimension, err = a.probeDimension(ctx); err != nil {
+			return err
+		}
+	}
+	a.metadata.Manifest = &manifest
+
+	// Новые и изменённые файлы переиндексируются, удалённые — убираются из индекса
+	if err := a.syncCorpus(ctx, files); err != nil {
+		return fmt.Errorf("failed to index documents: %w", err)
 	}
 
 	return nil
@@ -135,57 +243,61 @@ func (a *App) Shutdown() {
 	a.logger.Infof("Application resources released")
 }
 
-func (a *App) indexDocument(ctx context.Context, fileInfo os.FileInfo) error {
-
-	content, err := a.readFile(a.cfg.ReferenceDoc)
+// indexFile читает и разбивает один документ корпуса. Возвращает чанки и запись для Metadata.Files
+func (a *App) indexFile(path string) ([]chunker.Chunk, FileInfo, error) {
+	fileInfo, err := os.Stat(path)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return nil, FileInfo{}, err
 	}
 
-	a.logger.Infof("📄 File size: %d bytes", len(content))
-
-	chunkr, err := a.chunkerFactory.GetChunker(a.cfg.ReferenceDoc, a.cfg.ChunkMethod)
+	doc, err := a.readFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to get chunker: %w", err)
+		return nil, FileInfo{}, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	a.logger.Infof("🔧 Using chunker: %s", chunkr.Name())
+	a.logger.Infof("📄 %s: %d bytes", a.sourceName(path), len(doc.Text))
 
-	chunks, err := chunkr.Chunk(content, filepath.Base(a.cfg.ReferenceDoc))
+	chunks, _, err := a.chunkFile(a.chunkerFactory, doc, path)
 	if err != nil {
-		a.logger.Errorf("⚠️  Markdown chunker failed: %v", err)
-		a.logger.Infof("🔄 Falling back to text chunker...")
-
-		textChunker := chunker.NewTextChunker(chunker.Config{
-			MaxChunkSize: a.cfg.ChunkSize,
-			Overlap:      a.cfg.ChunkOverlap,
-		})
-
-		chunks, err = textChunker.Chunk(content, filepath.Base(a.cfg.ReferenceDoc))
-		if err != nil {
-			return fmt.Errorf("text chunker also failed: %w", err)
-		}
-
-		a.logger.Infof("✅ Text chunker succeeded")
+		return nil, FileInfo{}, err
 	}
 
 	if len(chunks) == 0 {
-		return fmt.Errorf("no chunks created from document")
+		return nil, FileInfo{}, fmt.Errorf("no chunks created from document")
 	}
 
 	a.logger.Infof("📦 Created %d chunks", len(chunks))
 
-	coll := a.db.GetCollection("docs", a.embeddingFunc)
-	if coll == nil {
-		coll, err = a.db.CreateCollection("docs", nil, a.embeddingFunc)
-		if err != nil {
-			return fmt.Errorf("failed to create collection: %w", err)
-		}
+	chunks = a.deduplicate(chunks)
+
+	// Front matter разбирает фабрика (только для markdown) и копирует в метаданные каждого чанка
+	frontMatter := frontMatterFromMetadata(chunks[0].Metadata)
+
+	hash, err := fileHash(path)
+	if err != nil {
+		return nil, FileInfo{}, err
 	}
 
-	a.logger.Infof("🔄 Adding chunks to vector database...")
+	return chunks, FileInfo{
+		Path:         a.sourceName(path),
+		LastModified: fileInfo.ModTime(),
+		Size:         fileInfo.Size(),
+		ContentHash:  hash,
+		FrontMatter:  frontMatter,
+	}, nil
+}
+
+// deduplicate сливает почти одинаковые чанки (overlap, повторяющиеся шаблонные абзацы) до векторизации
+func (a *App) deduplicate(chunks []chunker.Chunk) []chunker.Chunk {
+	deduped, removed := chunker.Deduplicate(chunks, a.cfg.DedupThreshold)
+	if removed > 0 {
+		a.logger.Infof("🧹 Merged %d near-duplicate chunks (threshold %.2f), %d left", removed, a.cfg.DedupThreshold, len(deduped))
+	}
+	return deduped
+}
 
-	// Подготавливаем документы для батчевого добавления
+// chunkDocuments превращает чанки в документы chromem
+func chunkDocuments(chunks []chunker.Chunk) []chromem.Document {
 	docs := make([]chromem.Document, len(chunks))
 	for i, chunk := range chunks {
 		docs[i] = chromem.Document{
@@ -199,83 +311,49 @@ func (a *App) indexDocument(ctx context.Context, fileInfo os.FileInfo) error {
 		docs[i].Metadata["source"] = chunk.Source
 		docs[i].Metadata["section"] = chunk.Section
 	}
+	return docs
+}
 
-	// Батчевое добавление с контролем concurrency (вместо последовательного с sleep)
-	if err := coll.AddDocuments(ctx, docs, a.cfg.MaxConcurrency); err != nil {
-		return fmt.Errorf("failed to add chunks to database: %w", err)
-	}
-
-	a.logger.Infof("✅ Successfully added %d chunks to vector database", len(chunks))
-
-	relPath := filepath.Base(a.cfg.ReferenceDoc)
-	a.metadata.Files[relPath] = FileInfo{
-		Path:         relPath,
-		LastModified: fileInfo.ModTime(),
-		Size:         fileInfo.Size(),
+// storeParents сохраняет родительские чанки в отдельную коллекцию. Поиск по ней не ведётся,
+// поэтому вместо embedding используется вектор-заглушка — API не вызывается
+func (a *App) storeParents(ctx context.Context, parents []chunker.Chunk) error {
+	coll := a.db.GetCollection(parentsCollection, nil)
+	if coll == nil {
+		var err error
+		coll, err = a.db.CreateCollection(parentsCollection, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create parents collection: %w", err)
+		}
 	}
 
-	if err := a.saveMetadata(); err != nil {
-		return fmt.Errorf("failed to save metadata: %w", err)
+	docs := chunkDocuments(parents)
+	for i := range docs {
+		docs[i].Embedding = []float32{1}
 	}
-
-	a.logger.Infof("💾 Saving vector database...")
-	if err := a.saveDB(); err != nil {
-		return fmt.Errorf("failed to save database: %w", err)
+	if err := coll.AddDocuments(ctx, docs, a.cfg.MaxConcurrency); err != nil {
+		return fmt.Errorf("failed to add parent chunks: %w", err)
 	}
 
-	a.logger.Infof("✅ Reference document indexed successfully")
+	a.logger.Infof("📚 Stored %d parent chunks", len(parents))
 	return nil
 }
 
-func (a *App) readFile(path string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(path))
-
-	switch ext {
-	case ".md", ".markdown", ".txt":
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return "", err
-		}
-		return string(data), nil
-	case ".pdf":
-		return a.readPDF(path)
-	default:
-		return "", fmt.Errorf("unsupported file format: %s", ext)
-	}
-}
-
-func (a *App) readPDF(path string) (string, error) {
-	f, r, err := pdf.Open(path)
+// chunkFile разбивает содержимое файла выбранным chunker'ом с запасной цепочкой CHUNK_FALLBACK.
+// Возвращает чанки и имя chunker'а, который их создал
+func (a *App) chunkFile(factory *chunker.Factory, doc Document, filePath string) ([]chunker.Chunk, string, error) {
+	chunks, chunkerName, err := factory.Chunk(doc.Text, a.sourceName(filePath), doc.Format, a.cfg.ChunkMethod)
+	// Фабрика нормализует чанки эталонным или проверочным pipeline: сводку пишет тот, что работал
+	a.referenceNormalize.LogSummary()
+	a.checkNormalize.LogSummary()
 	if err != nil {
-		return "", fmt.Errorf("failed to open PDF: %w", err)
+		return nil, "", fmt.Errorf("chunking failed: %w", err)
 	}
-	defer f.Close()
-
-	var textBuilder strings.Builder
-	totalPages := r.NumPage()
-
-	for pageNum := 1; pageNum <= totalPages; pageNum++ {
-		page := r.Page(pageNum)
-		if page.V.IsNull() {
-			continue
-		}
-
-		text, err := page.GetPlainText(nil)
-		if err != nil {
-			a.logger.Errorf("Warning: failed to extract text from page %d: %v", pageNum, err)
-			continue
+	if doc.Converted {
+		for i := range chunks {
+			chunks[i].Metadata[convertedByKey] = doc.Reader
 		}
-
-		textBuilder.WriteString(text)
-		textBuilder.WriteString("\n\n")
 	}
-
-	result := textBuilder.String()
-	if len(result) == 0 {
-		return "", fmt.Errorf("no text extracted from PDF")
-	}
-
-	return result, nil
+	return chunks, chunkerName, nil
 }
 
 func (a *App) loadMetadata() error {
@@ -302,7 +380,7 @@ func (a *App) saveMetadata() error {
 
 func (a *App) loadDB() error {
 	a.logger.Infof("Loading vector database from: %s", a.fileDB)
-	err := a.db.ImportFromFile(a.fileDB, "", "docs")
+	err := a.db.ImportFromFile(a.fileDB, "", "docs", parentsCollection)
 	if err != nil {
 		return fmt.Errorf("failed to import DB: %w", err)
 	}
@@ -319,7 +397,7 @@ func (a *App) loadDB() error {
 }
 
 func (a *App) saveDB() error {
-	return a.db.ExportToFile(a.fileDB, true, "", "docs")
+	return a.db.ExportToFile(a.fileDB, true, "", "docs", parentsCollection)
 }
 
 func (a *App) SetOutputPath(path string) {
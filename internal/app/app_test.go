@@ -0,0 +1,23 @@
+package app
+
+import (
+	"testing"
+
+	"console_rag/internal/config"
+)
+
+// newTestApp собирает приложение без LLM: параметры chunking по умолчанию (поверх полей cfg вне Chunking)
+// и reader'ы документов
+func newTestApp(t *testing.T, cfg *config.Config) *App {
+	t.Helper()
+	if err := config.InitChunking(cfg); err != nil {
+		t.Fatal(err)
+	}
+	a := &App{cfg: cfg, logger: &ConsoleLogger{}}
+	readers, err := a.newReaders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.readers = readers
+	return a
+}
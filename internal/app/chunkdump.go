@@ -0,0 +1,191 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"console_rag/internal/chunker"
+	"console_rag/internal/config"
+	"console_rag/internal/normalize"
+)
+
+// tinyChunkRatio — чанк меньше этой доли от CHUNK_SIZE считается крошечным
+const tinyChunkRatio = 0.1
+
+// ChunkRecord — строка JSONL с описанием чанка
+type ChunkRecord struct {
+	ID          string            `json:"id"`
+	ContentHash string            `json:"content_hash"`
+	Source      string            `json:"source"`
+	Section     string            `json:"section"`
+	Metadata    map[string]string `json:"metadata"`
+	Text        string            `json:"text"`
+	Bytes       int               `json:"bytes"`
+	Runes       int               `json:"runes"`
+	Tokens      int               `json:"tokens"`
+	Size        int               `json:"size"` // в единицах CHUNK_SIZE_UNIT
+}
+
+// DumpChunks разбивает файл на чанки и пишет их в JSONL (outPath или stdout), а в лог — сводку.
+// checkSide включает нормализацию проверяемого документа (NORMALIZE_CHECK) вместо эталонной.
+// Не обращается ни к embedding, ни к LLM: semantic chunker в этом режиме недоступен
+func DumpChunks(cfg *config.Config, filePath, outPath string, checkSide bool) error {
+	a := &App{
+		cfg:    cfg,
+		logger: &ConsoleLogger{},
+	}
+
+	steps := cfg.NormalizeReference
+	if checkSide {
+		steps = cfg.NormalizeCheck
+	}
+	norm, err := normalize.New(steps, a.logger.Infof, a.logger.Debugf)
+	if err != nil {
+		return fmt.Errorf("invalid normalization steps: %w", err)
+	}
+
+	a.chunkerConfig, err = newChunkerConfig(cfg, nil, norm)
+	if err != nil {
+		return err
+	}
+	a.chunkerFactory = chunker.NewFactory(a.chunkerConfig)
+	a.logger.Infof("🧽 Normalization: %v", norm.Names())
+
+	if a.readers, err = a.newReaders(); err != nil {
+		return err
+	}
+	doc, err := a.readFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	a.logger.Infof("📄 File loaded: %d bytes", len(doc.Text))
+
+	chunks, chunkerName, err := a.chunkFile(a.chunkerFactory, doc, filePath)
+	norm.LogSummary()
+	if err != nil {
+		return err
+	}
+	chunks = a.deduplicate(chunks)
+
+	var out io.Writer = os.Stdout
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	sizes := make([]int, len(chunks))
+	for i, chunk := range chunks {
+		sizes[i] = a.chunkerConfig.Size(chunk.Text)
+		record := ChunkRecord{
+			ID:          chunk.ID,
+			ContentHash: chunk.ContentHash,
+			Source:      chunk.Source,
+			Section:     chunk.Section,
+			Metadata:    chunk.Metadata,
+			Text:        chunk.Text,
+			Bytes:       len(chunk.Text),
+			Runes:       utf8.RuneCountInString(chunk.Text),
+			Tokens:      chunker.CountTokens(chunk.Text),
+			Size:        sizes[i],
+		}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write chunk: %w", err)
+		}
+	}
+
+	a.logChunkSummary(chunks, sizes, chunkerName)
+	if outPath != "" {
+		a.logger.Infof("💾 Chunks saved to: %s", outPath)
+	}
+
+	return nil
+}
+
+// logChunkSummary выводит выбранную стратегию, гистограмму размеров и число крошечных и слишком больших чанков
+func (a *App) logChunkSummary(chunks []chunker.Chunk, sizes []int, chunkerName string) {
+	maxSize := a.chunkerConfig.MaxChunkSize
+	unit := a.chunkerConfig.SizeUnit
+
+	methods := make(map[string]int)
+	for _, chunk := range chunks {
+		method := chunk.Metadata["method"]
+		if method == "" {
+			method = "-"
+		}
+		methods[method]++
+	}
+	var methodNames []string
+	for name := range methods {
+		methodNames = append(methodNames, name)
+	}
+	sort.Strings(methodNames)
+	var methodStats []string
+	for _, name := range methodNames {
+		methodStats = append(methodStats, fmt.Sprintf("%s=%d", name, methods[name]))
+	}
+
+	a.logger.Infof("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	a.logger.Infof("📊 Chunking summary:")
+	a.logger.Infof("   Chunker: %s", chunkerName)
+	a.logger.Infof("   Strategy: %s", strings.Join(methodStats, ", "))
+	a.logger.Infof("   Total chunks: %d", len(chunks))
+
+	if len(chunks) == 0 {
+		a.logger.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		return
+	}
+
+	// Гистограмма: 10 корзин по 10% от CHUNK_SIZE и отдельная корзина для превышения
+	const buckets = 10
+	counts := make([]int, buckets+1)
+	total, minSize, maxSeen := 0, sizes[0], 0
+	tiny, oversized := 0, 0
+	tinyLimit := int(float64(maxSize) * tinyChunkRatio)
+	for _, size := range sizes {
+		total += size
+		minSize = min(minSize, size)
+		maxSeen = max(maxSeen, size)
+		if size < tinyLimit {
+			tiny++
+		}
+		if size > maxSize {
+			oversized++
+			counts[buckets]++
+			continue
+		}
+		bucket := size * buckets / max(maxSize, 1)
+		counts[min(bucket, buckets-1)]++
+	}
+
+	a.logger.Infof("   Size (%s): min=%d avg=%d max=%d, limit=%d", unit, minSize, total/len(sizes), maxSeen, maxSize)
+
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c)
+	}
+	for i, c := range counts {
+		label := fmt.Sprintf("%5d-%-5d", i*maxSize/buckets, (i+1)*maxSize/buckets)
+		if i == buckets {
+			label = fmt.Sprintf("  >%-8d", maxSize)
+		}
+		bar := strings.Repeat("█", (c*40+peak-1)/max(peak, 1))
+		a.logger.Infof("   %s │%s %d", label, bar, c)
+	}
+
+	a.logger.Infof("   Tiny (< %d %s): %d", tinyLimit, unit, tiny)
+	a.logger.Infof("   Oversized (> %d %s): %d", maxSize, unit, oversized)
+	a.logger.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+}
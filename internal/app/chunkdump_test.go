@@ -0,0 +1,74 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+
+	"console_rag/internal/config"
+)
+
+// Пробный прогон пишет по строке JSONL на чанк: ID, секцию, метаданные (в том числе путь заголовков
+// и front matter) и размеры. Embedding и LLM не настроены — DumpChunks к ним не обращается
+func TestDumpChunks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.md")
+	content := "---\ntitle: Правила\n---\n\n" + testDocument(
+		"Работнику предоставляется ежегодный оплачиваемый отпуск.",
+		"Заработная плата выплачивается дважды в месяц.",
+		"Рабочая неделя составляет сорок часов.")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	if err := config.InitChunking(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.ChunkSize = 80
+	out := filepath.Join(dir, "chunks.jsonl")
+	if err := DumpChunks(cfg, path, out, false); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []ChunkRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record ChunkRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	for i, r := range records {
+		section := "Раздел " + string(rune('А'+i))
+		if r.Section != section || r.Metadata["heading_path"] != section || r.Metadata["doc_title"] != "Правила" {
+			t.Errorf("record %d: section %q, metadata %v", i, r.Section, r.Metadata)
+		}
+		if r.ID == "" || r.Source != "rules.md" {
+			t.Errorf("record %d: id %q, source %q", i, r.ID, r.Source)
+		}
+		if r.Runes != utf8.RuneCountInString(r.Text) || r.Bytes != len(r.Text) || r.Size != r.Runes || r.Tokens == 0 {
+			t.Errorf("record %d: sizes %d bytes, %d runes, %d tokens, size %d", i, r.Bytes, r.Runes, r.Tokens, r.Size)
+		}
+	}
+
+	// Semantic chunker'у нужен embedding API, которого в пробном прогоне нет
+	cfg.ChunkMethod = "semantic"
+	cfg.ChunkFallback = nil
+	if err := DumpChunks(cfg, path, out, false); err == nil {
+		t.Error("semantic chunking succeeded without an embedding API")
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// globMeta — символы шаблона filepath.Match
+const globMeta = "*?["
+
+// isGlob проверяет, что REFERENCE_DOC — шаблон ("docs/*.md"), а не путь
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, globMeta)
+}
+
+// corpusName — имя файлов БД и метаданных для эталона: имя документа без расширения, имя директории
+// или шаблон с заменой спецсимволов ("docs/*.md" → "docs_all")
+func corpusName(reference string) string {
+	reference = filepath.Clean(reference)
+	if isGlob(reference) {
+		base := strings.TrimSuffix(filepath.Base(reference), filepath.Ext(reference))
+		name := filepath.Base(filepath.Dir(reference)) + "_" + base
+		return strings.NewReplacer("*", "all", "?", "_", "[", "_", "]", "_").Replace(name)
+	}
+	if info, err := os.Stat(reference); err == nil && info.IsDir() {
+		return filepath.Base(reference)
+	}
+	return strings.TrimSuffix(filepath.Base(reference), filepath.Ext(reference))
+}
+
+// corpusRoot — директория, от которой считаются имена документов корпуса: сама директория REFERENCE_DOC,
+// часть шаблона до первого спецсимвола или директория единственного документа
+func corpusRoot(reference string) string {
+	reference = filepath.Clean(reference)
+	if isGlob(reference) {
+		root := reference[:strings.IndexAny(reference, globMeta)]
+		return filepath.Dir(root + "x")
+	}
+	if info, err := os.Stat(reference); err == nil && info.IsDir() {
+		return reference
+	}
+	return filepath.Dir(reference)
+}
+
+// sourceName — имя документа в индексе (chunker.Chunk.Source, ключ Metadata.Files, ID чанков): путь
+// относительно корня корпуса ("tk/index.html"). Файл вне корпуса (проверяемый документ) — по имени файла
+func (a *App) sourceName(path string) string {
+	if a.cfg.ReferenceDoc == "" {
+		return filepath.Base(path)
+	}
+	rel, err := filepath.Rel(corpusRoot(a.cfg.ReferenceDoc), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// referenceFiles раскрывает REFERENCE_DOC в список файлов корпуса: один файл, все читаемые файлы
+// директории (рекурсивно, без скрытых; внешний конвертер — только для READER_COMMAND_EXTENSIONS) или файлы по шаблону
+func (a *App) referenceFiles(reference string) ([]string, error) {
+	var files []string
+	switch info, err := os.Stat(reference); {
+	case isGlob(reference) && err != nil:
+		matches, err := filepath.Glob(reference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference pattern %s: %w", reference, err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
+			}
+		}
+	case err != nil:
+		return nil, fmt.Errorf("reference document not found: %w", err)
+	case info.IsDir():
+		err := filepath.WalkDir(reference, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != reference && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			// READER_COMMAND без списка расширений конвертирует только файлы, указанные явно
+			if _, err := a.readers.match(path); err != nil {
+				a.logger.Debugf("Skipping %s: %v", path, err)
+				return nil
+			}
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reference directory: %w", err)
+		}
+	default:
+		files = []string{reference}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no reference documents found: %s", reference)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"console_rag/internal/config"
+)
+
+// Страницы кодексов, сохранённые как index.html в разных директориях, — разные документы корпуса
+func TestReferenceFilesWithSameBaseName(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"tk/index.html", "koap/index.html"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<html><body><p>Статья 1. Текст</p></body></html>"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, reference := range []string{root, filepath.Join(root, "*", "index.html")} {
+		a := newTestApp(t, &config.Config{ReferenceDoc: reference})
+		files, err := a.referenceFiles(reference)
+		if err != nil {
+			t.Fatalf("%s: %v", reference, err)
+		}
+		var names []string
+		for _, path := range files {
+			names = append(names, a.sourceName(path))
+		}
+		if want := []string{"koap/index.html", "tk/index.html"}; !slices.Equal(names, want) {
+			t.Errorf("%s: sources %q, want %q", reference, names, want)
+		}
+	}
+}
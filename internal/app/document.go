@@ -14,44 +14,30 @@ import (
 
 // processInputDocument обрабатывает входной файл
 func (a *App) processInputDocument(ctx context.Context, filePath string) error {
-	content, err := a.readFile(filePath)
+	doc, err := a.readFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	a.logger.Infof("📄 File loaded: %d bytes", len(content))
+	a.logger.Infof("📄 File loaded: %d bytes", len(doc.Text))
 
 	// Определяем chunker
 
 	chunks := []chunker.Chunk{}
 	if a.cfg.RunChunker {
-		chunkr, err := a.chunkerFactory.GetChunker(filePath, a.cfg.ChunkMethod)
+		chunks, _, err = a.chunkFile(a.checkFactory, doc, filePath)
 		if err != nil {
-			return fmt.Errorf("failed to get chunker: %w", err)
-		}
-
-		// Разбиваем на чанки
-		chunks, err = chunkr.Chunk(content, filepath.Base(filePath))
-		if err != nil {
-			// Fallback на text chunker
-			a.logger.Errorf("⚠️  Chunker failed: %v, falling back to text chunker", err)
-			textChunker := chunker.NewTextChunker(chunker.Config{
-				MaxChunkSize: a.cfg.ChunkSize,
-				Overlap:      a.cfg.ChunkOverlap,
-			})
-			chunks, err = textChunker.Chunk(content, filepath.Base(filePath))
-			if err != nil {
-				return fmt.Errorf("text chunker failed: %w", err)
-			}
+			return err
 		}
 
 		a.logger.Infof("📦 Split into %d chunks", len(chunks))
 	} else {
 		chunks = append(chunks, chunker.Chunk{
-			Text:    content,
+			Text:    a.checkNormalize.Apply(doc.Text, "Full Document"),
 			Section: "Full Document",
-			Source:  filepath.Base(filePath),
+			Source:  a.sourceName(filePath),
 		})
+		a.checkNormalize.LogSummary()
 	}
 
 	// Semaphore для контроля concurrency
@@ -70,8 +56,9 @@ func (a *App) processInputDocument(ctx context.Context, filePath string) error {
 			defer func() { <-sem }()
 
 			result := &AnalysisResult{
-				ChunkIndex:   idx + 1,
-				ChunkSection: ch.Section,
+				ChunkIndex:    idx + 1,
+				ChunkSection:  ch.Section,
+				ChunkPosition: positionFromMetadata(ch.Metadata),
 			}
 
 			// Поиск релевантных секций
@@ -86,6 +73,7 @@ func (a *App) processInputDocument(ctx context.Context, filePath string) error {
 			}
 
 			result.ReferenceCount = len(searchResults)
+			result.References = searchResults
 
 			prompt := a.buildAnalysisPrompt(ch.Text, searchResults)
 			a.logger.Debugf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
@@ -109,7 +97,7 @@ func (a *App) processInputDocument(ctx context.Context, filePath string) error {
 
 			mu.Lock()
 			a.logger.Infof("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-			a.logger.Infof("Chunk %d/%d: %s", result.ChunkIndex, len(chunks), result.ChunkSection)
+			a.logger.Infof("Chunk %d/%d: %s %s", result.ChunkIndex, len(chunks), result.ChunkSection, result.ChunkPosition)
 			a.logger.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 			a.logger.Infof("🔍 Found %d relevant sections", result.ReferenceCount)
 			a.logger.Infof("\n Analysis:\n%s", result.Analysis)
@@ -167,8 +155,10 @@ func (a *App) processInputDocument(ctx context.Context, filePath string) error {
 type AnalysisResult struct {
 	ChunkIndex     int
 	ChunkSection   string
+	ChunkPosition  Position // Положение фрагмента в проверяемом документе
 	Analysis       string
 	ReferenceCount int
+	References     []SearchResult
 	Error          error
 }
 
@@ -201,13 +191,57 @@ func saveAnalysisResults(analysis *DocumentAnalysis, outputPath string) error {
 		}
 		if result.Error != nil {
 			buf.WriteString(fmt.Sprintf("### ⚠️ Chunk %d: %s — ОШИБКА\n\n", result.ChunkIndex, result.ChunkSection))
+			if loc := result.ChunkPosition.String(); loc != "" {
+				buf.WriteString(fmt.Sprintf("**Положение:** %s\n\n", loc))
+			}
 			buf.WriteString(fmt.Sprintf("**Ошибка:** %s\n\n", result.Error.Error()))
 			buf.WriteString("---\n\n")
 			continue
 		}
 
 		buf.WriteString(fmt.Sprintf("### Chunk %d: %s\n\n", result.ChunkIndex, result.ChunkSection))
+		if loc := result.ChunkPosition.String(); loc != "" {
+			buf.WriteString(fmt.Sprintf("**Положение:** %s\n\n", loc))
+		}
 		buf.WriteString(fmt.Sprintf("**Релевантных секций найдено:** %d\n\n", result.ReferenceCount))
+		for _, ref := range result.References {
+			buf.WriteString(fmt.Sprintf("- %s", ref.Section))
+			if loc := ref.Position.String(); loc != "" {
+				buf.WriteString(fmt.Sprintf(" (%s, %s)", ref.Source, loc))
+			} else if ref.Source != "" {
+				buf.WriteString(fmt.Sprintf(" (%s)", ref.Source))
+			}
+			if ref.Repealed {
+				buf.WriteString(" — утратила силу")
+			}
+			buf.WriteString(fmt.Sprintf(" — similarity %.2f\n", ref.Similarity))
+			if ref.AmendedBy != "" {
+				buf.WriteString(fmt.Sprintf("  - в редакции: %s\n", ref.AmendedBy))
+			} else if len(ref.Amendments) > 0 {
+				buf.WriteString("  - пометки об изменениях:\n")
+			}
+			for _, note := range ref.Amendments {
+				buf.WriteString(fmt.Sprintf("    - %s\n", note))
+			}
+			if len(ref.Document) > 0 {
+				buf.WriteString(fmt.Sprintf("  - документ: %s\n", documentLabel(ref.Document)))
+			}
+			for _, dup := range ref.Duplicates {
+				buf.WriteString(fmt.Sprintf("  - тот же текст: %s", dup.Section))
+				if dup.Article != "" && !strings.Contains(dup.Section, dup.Article) {
+					buf.WriteString(fmt.Sprintf(" (статья %s)", dup.Article))
+				}
+				if loc := dup.Position.String(); loc != "" {
+					buf.WriteString(fmt.Sprintf(" (%s, %s)", dup.Source, loc))
+				} else if dup.Source != "" {
+					buf.WriteString(fmt.Sprintf(" (%s)", dup.Source))
+				}
+				buf.WriteString("\n")
+			}
+		}
+		if len(result.References) > 0 {
+			buf.WriteString("\n")
+		}
 		buf.WriteString("**Анализ:**\n\n")
 		buf.WriteString(result.Analysis)
 		buf.WriteString("\n\n---\n\n")
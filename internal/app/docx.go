@@ -0,0 +1,619 @@
+package app
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// docxMaxLevels — число уровней нумерации в WordprocessingML
+const docxMaxLevels = 9
+
+var (
+	// Встроенные стили заголовков: "heading 1" в styles.xml, "Заголовок 1" в русских шаблонах
+	reDocxHeadingStyle = regexp.MustCompile(`(?i)^(?:heading|заголовок)\s*([1-9])$`)
+
+	docxRussianLetters = []rune("абвгдежзиклмнопрстуфхцчшщэюя")
+)
+
+// docxVal — элемент вида <w:numId w:val="3"/>
+type docxVal struct {
+	Val string `xml:"val,attr"`
+}
+
+type docxStylesXML struct {
+	Styles []struct {
+		ID      string  `xml:"styleId,attr"`
+		Name    docxVal `xml:"name"`
+		BasedOn docxVal `xml:"basedOn"`
+		PPr     struct {
+			OutlineLvl *docxVal `xml:"outlineLvl"`
+			NumPr      struct {
+				NumID *docxVal `xml:"numId"`
+				Ilvl  *docxVal `xml:"ilvl"`
+			} `xml:"numPr"`
+		} `xml:"pPr"`
+	} `xml:"style"`
+}
+
+type docxNumberingXML struct {
+	Abstract []struct {
+		ID     string `xml:"abstractNumId,attr"`
+		Levels []struct {
+			Ilvl    int     `xml:"ilvl,attr"`
+			Start   docxVal `xml:"start"`
+			NumFmt  docxVal `xml:"numFmt"`
+			LvlText docxVal `xml:"lvlText"`
+		} `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		ID         string  `xml:"numId,attr"`
+		AbstractID docxVal `xml:"abstractNumId"`
+	} `xml:"num"`
+}
+
+// docxStyle — стиль абзаца: уровень заголовка и нумерация, заданная в стиле
+type docxStyle struct {
+	name    string
+	basedOn string
+	outline string // w:outlineLvl, 0 — первый уровень
+	numID   string
+	ilvl    string
+}
+
+// docxLevel — формат одного уровня списка
+type docxLevel struct {
+	format string // decimal, bullet, lowerLetter, russianLower ...
+	text   string // шаблон "%1.%2."
+	start  int
+}
+
+// docxList — абстрактная нумерация: счётчики общие для всех w:num, которые на неё ссылаются
+type docxList struct {
+	levels   [docxMaxLevels]docxLevel
+	counters [docxMaxLevels]int
+	started  [docxMaxLevels]bool // счётчик уровня уже получил start: w:start бывает и 0
+}
+
+// docxParagraph — абзац документа
+type docxParagraph struct {
+	style   string
+	outline string
+	numID   string
+	ilvl    string
+	text    strings.Builder
+	boxes   []string // Markdown-блоки надписей (w:txbxContent), привязанных к абзацу
+}
+
+// docxConverter переводит WordprocessingML в Markdown: стили заголовков → "#", нумерация → "1.", "1.2.", "а)"
+// или "-", таблицы → GFM-таблицы, надписи — отдельными блоками после своего абзаца.
+// Колонтитулы, сноски и удалённый при рецензировании текст не попадают в результат
+type docxConverter struct {
+	styles map[string]docxStyle
+	lists  map[string]*docxList // w:numId → нумерация
+}
+
+// readDOCX извлекает текст .docx в виде Markdown, чтобы его мог разбить markdown chunker
+func (a *App) readDOCX(path string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOCX: %w", err)
+	}
+	defer zr.Close()
+
+	parts := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+	document, ok := parts["word/document.xml"]
+	if !ok {
+		return "", fmt.Errorf("failed to open DOCX: word/document.xml not found")
+	}
+
+	c := &docxConverter{
+		styles: make(map[string]docxStyle),
+		lists:  make(map[string]*docxList),
+	}
+	if f, ok := parts["word/styles.xml"]; ok {
+		if err := c.loadStyles(f); err != nil {
+			a.logger.Errorf("Warning: failed to read DOCX styles: %v", err)
+		}
+	}
+	if f, ok := parts["word/numbering.xml"]; ok {
+		if err := c.loadNumbering(f); err != nil {
+			a.logger.Errorf("Warning: failed to read DOCX numbering: %v", err)
+		}
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX: %w", err)
+	}
+	defer rc.Close()
+
+	result, err := c.convert(xml.NewDecoder(rc))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse DOCX: %w", err)
+	}
+	if strings.TrimSpace(result) == "" {
+		return "", fmt.Errorf("no text extracted from DOCX")
+	}
+	return result, nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func (c *docxConverter) loadStyles(f *zip.File) error {
+	var styles docxStylesXML
+	if err := decodeZipXML(f, &styles); err != nil {
+		return err
+	}
+	for _, s := range styles.Styles {
+		style := docxStyle{name: s.Name.Val, basedOn: s.BasedOn.Val}
+		if s.PPr.OutlineLvl != nil {
+			style.outline = s.PPr.OutlineLvl.Val
+		}
+		if s.PPr.NumPr.NumID != nil {
+			style.numID = s.PPr.NumPr.NumID.Val
+		}
+		if s.PPr.NumPr.Ilvl != nil {
+			style.ilvl = s.PPr.NumPr.Ilvl.Val
+		}
+		c.styles[s.ID] = style
+	}
+	return nil
+}
+
+func (c *docxConverter) loadNumbering(f *zip.File) error {
+	var numbering docxNumberingXML
+	if err := decodeZipXML(f, &numbering); err != nil {
+		return err
+	}
+
+	abstract := make(map[string]docxList)
+	for _, an := range numbering.Abstract {
+		var list docxList
+		for _, lvl := range an.Levels {
+			if lvl.Ilvl < 0 || lvl.Ilvl >= docxMaxLevels {
+				continue
+			}
+			start, err := strconv.Atoi(lvl.Start.Val)
+			if err != nil {
+				start = 1
+			}
+			list.levels[lvl.Ilvl] = docxLevel{format: lvl.NumFmt.Val, text: lvl.LvlText.Val, start: start}
+		}
+		abstract[an.ID] = list
+	}
+
+	shared := make(map[string]*docxList)
+	for _, num := range numbering.Nums {
+		id := num.AbstractID.Val
+		if _, ok := abstract[id]; !ok {
+			continue
+		}
+		if shared[id] == nil {
+			list := abstract[id]
+			shared[id] = &list
+		}
+		c.lists[num.ID] = shared[id]
+	}
+	return nil
+}
+
+// convert проходит по телу документа и собирает Markdown-блоки
+func (c *docxConverter) convert(dec *xml.Decoder) (string, error) {
+	var blocks []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "p":
+			p, err := c.readParagraph(dec)
+			if err != nil {
+				return "", err
+			}
+			if block := c.paragraphMarkdown(p); block != "" {
+				blocks = append(blocks, block)
+			}
+			blocks = append(blocks, p.boxes...)
+		case "tbl":
+			rows, err := c.readTable(dec)
+			if err != nil {
+				return "", err
+			}
+			if block := tableMarkdown(rows); block != "" {
+				blocks = append(blocks, block)
+			}
+		case "sectPr":
+			// Колонтитулы подключаются из свойств раздела — их не читаем
+			if err := dec.Skip(); err != nil {
+				return "", err
+			}
+		}
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+// readParagraph читает w:p до закрывающего тега
+func (c *docxConverter) readParagraph(dec *xml.Decoder) (*docxParagraph, error) {
+	p := &docxParagraph{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "p" {
+				return p, nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pStyle":
+				p.style = xmlAttr(t, "val")
+			case "outlineLvl":
+				p.outline = xmlAttr(t, "val")
+			case "numId":
+				p.numID = xmlAttr(t, "val")
+			case "ilvl":
+				p.ilvl = xmlAttr(t, "val")
+			case "t":
+				var text string
+				if err := dec.DecodeElement(&text, &t); err != nil {
+					return nil, err
+				}
+				p.text.WriteString(text)
+			case "tab":
+				p.text.WriteString(" ")
+			case "br", "cr":
+				p.text.WriteString("\n")
+			case "noBreakHyphen":
+				p.text.WriteString("-")
+			case "txbxContent":
+				// Абзацы надписи вложены в абзац, к которому она привязана: читаем их отдельными блоками
+				boxes, err := c.readTextBox(dec)
+				if err != nil {
+					return nil, err
+				}
+				p.boxes = append(p.boxes, boxes...)
+			case "tabs", "Fallback", "footnoteReference", "endnoteReference":
+				// Позиции табуляции — не текст; mc:Fallback повторяет надпись из mc:Choice в формате VML,
+				// сноски лежат в отдельных частях документа
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+// readTextBox читает w:txbxContent до закрывающего тега: абзацы и таблицы надписи как Markdown-блоки
+func (c *docxConverter) readTextBox(dec *xml.Decoder) ([]string, error) {
+	var blocks []string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "txbxContent" {
+				return blocks, nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				p, err := c.readParagraph(dec)
+				if err != nil {
+					return nil, err
+				}
+				if block := c.paragraphMarkdown(p); block != "" {
+					blocks = append(blocks, block)
+				}
+				blocks = append(blocks, p.boxes...)
+			case "tbl":
+				rows, err := c.readTable(dec)
+				if err != nil {
+					return nil, err
+				}
+				if block := tableMarkdown(rows); block != "" {
+					blocks = append(blocks, block)
+				}
+			}
+		}
+	}
+}
+
+// readTable читает w:tbl: строки из ячеек, вложенные таблицы — текстом в ячейке
+func (c *docxConverter) readTable(dec *xml.Decoder) ([][]string, error) {
+	var rows [][]string
+	var cell []string
+	span := 1
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "tbl":
+				return rows, nil
+			case "tc":
+				if len(rows) > 0 {
+					row := &rows[len(rows)-1]
+					*row = append(*row, strings.Join(cell, " "))
+					// Объединённые по горизонтали ячейки занимают несколько колонок
+					for ; span > 1; span-- {
+						*row = append(*row, "")
+					}
+				}
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tr":
+				rows = append(rows, nil)
+			case "tc":
+				cell, span = nil, 1
+			case "gridSpan":
+				if n, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && n > 1 {
+					span = n
+				}
+			case "p":
+				p, err := c.readParagraph(dec)
+				if err != nil {
+					return nil, err
+				}
+				if text := c.paragraphText(p); text != "" {
+					cell = append(cell, text)
+				}
+				cell = append(cell, p.boxes...)
+			case "tbl":
+				nested, err := c.readTable(dec)
+				if err != nil {
+					return nil, err
+				}
+				for _, row := range nested {
+					cell = append(cell, strings.Join(row, " "))
+				}
+			}
+		}
+	}
+}
+
+// paragraphText возвращает текст абзаца с номером пункта, без разметки Markdown
+func (c *docxConverter) paragraphText(p *docxParagraph) string {
+	text := strings.TrimSpace(p.text.String())
+	if text == "" {
+		return ""
+	}
+	if marker := c.listMarker(p); marker != "" && marker != "-" {
+		return marker + " " + text
+	}
+	return text
+}
+
+// paragraphMarkdown переводит абзац в Markdown-блок: заголовок, пункт списка или обычный абзац
+func (c *docxConverter) paragraphMarkdown(p *docxParagraph) string {
+	text := strings.TrimSpace(p.text.String())
+	if text == "" {
+		return ""
+	}
+	// Переносы строк внутри заголовка и пункта списка ломают Markdown
+	text = strings.Join(strings.Fields(strings.ReplaceAll(text, "\n", " ")), " ")
+
+	marker := c.listMarker(p)
+	if level := c.headingLevel(p); level > 0 {
+		if marker != "" && marker != "-" {
+			text = marker + " " + text
+		}
+		return strings.Repeat("#", level) + " " + text
+	}
+
+	switch marker {
+	case "":
+		return text
+	case "-":
+		ilvl, _ := strconv.Atoi(c.numbering(p).ilvl)
+		return strings.Repeat("  ", ilvl) + "- " + text
+	default:
+		return marker + " " + text
+	}
+}
+
+// headingLevel возвращает уровень заголовка (1–6) по стилю абзаца или его outlineLvl, 0 — не заголовок
+func (c *docxConverter) headingLevel(p *docxParagraph) int {
+	outline := p.outline
+	for id, depth := p.style, 0; id != "" && depth < 10; depth++ {
+		style, ok := c.styles[id]
+		if !ok {
+			break
+		}
+		if m := reDocxHeadingStyle.FindStringSubmatch(style.name); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return min(n, 6)
+		}
+		if strings.EqualFold(style.name, "title") {
+			return 1
+		}
+		if outline == "" {
+			outline = style.outline
+		}
+		id = style.basedOn
+	}
+
+	// outlineLvl 9 — основной текст
+	if n, err := strconv.Atoi(outline); err == nil && n < 9 {
+		return min(n+1, 6)
+	}
+	return 0
+}
+
+// numbering возвращает нумерацию абзаца: собственную или из стиля (нумерованные заголовки)
+func (c *docxConverter) numbering(p *docxParagraph) docxStyle {
+	num := docxStyle{numID: p.numID, ilvl: p.ilvl}
+	for id, depth := p.style, 0; id != "" && depth < 10; depth++ {
+		style, ok := c.styles[id]
+		if !ok {
+			break
+		}
+		if num.numID == "" {
+			num.numID = style.numID
+		}
+		if num.ilvl == "" {
+			num.ilvl = style.ilvl
+		}
+		id = style.basedOn
+	}
+	return num
+}
+
+// listMarker продвигает счётчики нумерации и возвращает маркер пункта: "-" для маркированного списка,
+// "3.", "1.2." или "б)" для нумерованного, "" — абзац без нумерации
+func (c *docxConverter) listMarker(p *docxParagraph) string {
+	num := c.numbering(p)
+	if num.numID == "" || num.numID == "0" {
+		return ""
+	}
+	list, ok := c.lists[num.numID]
+	if !ok {
+		return ""
+	}
+	ilvl, err := strconv.Atoi(num.ilvl)
+	if err != nil || ilvl < 0 || ilvl >= docxMaxLevels {
+		ilvl = 0
+	}
+
+	level := list.levels[ilvl]
+	if level.format == "bullet" {
+		return "-"
+	}
+	if !list.started[ilvl] {
+		list.counters[ilvl] = level.start
+		list.started[ilvl] = true
+	} else {
+		list.counters[ilvl]++
+	}
+	for i := ilvl + 1; i < docxMaxLevels; i++ {
+		list.started[i] = false
+	}
+	if level.format == "none" {
+		return ""
+	}
+
+	text := level.text
+	if text == "" {
+		text = "%" + strconv.Itoa(ilvl+1) + "."
+	}
+	for i := 0; i <= ilvl; i++ {
+		counter := list.counters[i]
+		if !list.started[i] {
+			counter = list.levels[i].start
+		}
+		text = strings.ReplaceAll(text, "%"+strconv.Itoa(i+1), formatDocxNumber(counter, list.levels[i].format))
+	}
+	return strings.TrimSpace(text)
+}
+
+// formatDocxNumber форматирует номер пункта по w:numFmt. Буквами и римскими цифрами
+// записываются номера от 1, меньшие — арабскими цифрами
+func formatDocxNumber(n int, format string) string {
+	if n < 1 {
+		format = "decimal"
+	}
+	switch format {
+	case "lowerLetter", "upperLetter":
+		s := strings.Repeat(string(rune('a'+(n-1)%26)), (n-1)/26+1)
+		if format == "upperLetter" {
+			return strings.ToUpper(s)
+		}
+		return s
+	case "russianLower", "russianUpper":
+		letters := docxRussianLetters
+		s := strings.Repeat(string(letters[(n-1)%len(letters)]), (n-1)/len(letters)+1)
+		if format == "russianUpper" {
+			return strings.ToUpper(s)
+		}
+		return s
+	case "lowerRoman":
+		return strings.ToLower(romanNumeral(n))
+	case "upperRoman":
+		return romanNumeral(n)
+	case "decimalZero":
+		return fmt.Sprintf("%02d", n)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+func romanNumeral(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	var b strings.Builder
+	for i, v := range values {
+		for n >= v {
+			b.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+// tableMarkdown собирает GFM-таблицу; первая строка считается заголовком
+func tableMarkdown(rows [][]string) string {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		b.WriteString("|")
+		for col := 0; col < columns; col++ {
+			cell := ""
+			if col < len(row) {
+				cell = strings.Join(strings.Fields(row[col]), " ")
+				cell = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func xmlAttr(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"console_rag/internal/config"
+)
+
+const docxTestStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/></w:style>
+  <w:style w:type="paragraph" w:styleId="a2"><w:name w:val="Заголовок 2"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Article"><w:name w:val="Статья"/><w:basedOn w:val="a2"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Point">
+    <w:name w:val="Пункт"/>
+    <w:pPr><w:numPr><w:numId w:val="1"/></w:numPr></w:pPr>
+  </w:style>
+</w:styles>`
+
+const docxTestNumbering = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:abstractNum w:abstractNumId="0">
+    <w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl>
+    <w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1.%2."/></w:lvl>
+  </w:abstractNum>
+  <w:abstractNum w:abstractNumId="1">
+    <w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="russianLower"/><w:lvlText w:val="%1)"/></w:lvl>
+  </w:abstractNum>
+  <w:abstractNum w:abstractNumId="2">
+    <w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/><w:lvlText w:val="•"/></w:lvl>
+  </w:abstractNum>
+  <w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+  <w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>
+  <w:num w:numId="3"><w:abstractNumId w:val="2"/></w:num>
+  <w:num w:numId="4"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`
+
+const docxTestDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006"
+  xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"
+  xmlns:v="urn:schemas-microsoft-com:vml">
+<w:body>
+  <w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>Положение об оплате труда</w:t></w:r></w:p>
+  <w:p><w:pPr><w:pStyle w:val="Article"/></w:pPr><w:r><w:t>Общие</w:t></w:r><w:r><w:t xml:space="preserve"> положения</w:t></w:r></w:p>
+  <w:p><w:pPr><w:pStyle w:val="Point"/></w:pPr><w:r><w:t>Первый пункт.</w:t></w:r></w:p>
+  <w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Подпункт.</w:t></w:r></w:p>
+  <w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Ещё подпункт.</w:t></w:r></w:p>
+  <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="4"/></w:numPr></w:pPr><w:r><w:t>Второй пункт той же нумерации.</w:t></w:r></w:p>
+  <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>оклад;</w:t></w:r></w:p>
+  <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t>премия.</w:t></w:r></w:p>
+  <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr></w:pPr><w:r><w:t>Маркированный пункт</w:t></w:r></w:p>
+  <w:p>
+    <w:r><w:t>Абзац с надписью.</w:t></w:r>
+    <w:r><mc:AlternateContent>
+      <mc:Choice Requires="wps"><w:drawing><wps:txbx><w:txbxContent>
+        <w:p><w:r><w:t>Текст надписи</w:t></w:r></w:p>
+      </w:txbxContent></wps:txbx></w:drawing></mc:Choice>
+      <mc:Fallback><w:pict><v:shape><v:textbox><w:txbxContent>
+        <w:p><w:r><w:t>Текст надписи</w:t></w:r></w:p>
+      </w:txbxContent></v:textbox></v:shape></w:pict></mc:Fallback>
+    </mc:AlternateContent></w:r>
+    <w:r><w:t xml:space="preserve"> Продолжение абзаца.</w:t></w:r>
+  </w:p>
+  <w:tbl>
+    <w:tr>
+      <w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>Должность и оклад</w:t></w:r></w:p></w:tc>
+      <w:tc><w:p><w:r><w:t>Примечание</w:t></w:r></w:p></w:tc>
+    </w:tr>
+    <w:tr>
+      <w:tc><w:p><w:r><w:t>Инженер</w:t></w:r></w:p></w:tc>
+      <w:tc><w:p><w:r><w:t>50000</w:t></w:r></w:p></w:tc>
+      <w:tc><w:tbl>
+        <w:tr><w:tc><w:p><w:r><w:t>Надбавка</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>10%</w:t></w:r></w:p></w:tc></w:tr>
+      </w:tbl></w:tc>
+    </w:tr>
+  </w:tbl>
+  <w:sectPr><w:headerReference w:type="default"/></w:sectPr>
+</w:body>
+</w:document>`
+
+// Минимальный .docx собирается в памяти: стили заголовков, общие счётчики нумерации,
+// объединённые ячейки, вложенная таблица и надпись, которая в mc:Fallback повторяется
+func TestReadDOCX(t *testing.T) {
+	path := writeTestDOCX(t, docxTestDocument, docxTestNumbering)
+
+	a := newTestApp(t, &config.Config{})
+	got, err := a.readDOCX(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"# Положение об оплате труда",
+		"## Общие положения",
+		"1. Первый пункт.",
+		"1.1. Подпункт.",
+		"1.2. Ещё подпункт.",
+		"2. Второй пункт той же нумерации.",
+		"а) оклад;",
+		"б) премия.",
+		"- Маркированный пункт",
+		"Абзац с надписью. Продолжение абзаца.",
+		"Текст надписи",
+		"| Должность и оклад |  | Примечание |\n" +
+			"| --- | --- | --- |\n" +
+			"| Инженер | 50000 | Надбавка 10% |",
+	}, "\n\n") + "\n"
+	if got != want {
+		t.Errorf("readDOCX:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// Нумерация с w:start="0" — допустимый DOCX: буквенный уровень не должен падать,
+// а нулевой счётчик — начинаться заново на каждом пункте
+func TestReadDOCXZeroStart(t *testing.T) {
+	numbering := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:abstractNum w:abstractNumId="0">
+    <w:lvl w:ilvl="0"><w:start w:val="0"/><w:numFmt w:val="russianLower"/><w:lvlText w:val="%1)"/></w:lvl>
+  </w:abstractNum>
+  <w:abstractNum w:abstractNumId="1">
+    <w:lvl w:ilvl="0"><w:start w:val="0"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl>
+  </w:abstractNum>
+  <w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+  <w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>
+</w:numbering>`
+	var body strings.Builder
+	for _, item := range []struct{ numID, text string }{
+		{"1", "нулевой;"}, {"1", "первый;"}, {"1", "второй."},
+		{"2", "Нулевой пункт."}, {"2", "Первый пункт."},
+	} {
+		body.WriteString(`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="` + item.numID +
+			`"/></w:numPr></w:pPr><w:r><w:t>` + item.text + `</w:t></w:r></w:p>`)
+	}
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() + `</w:body></w:document>`
+
+	a := newTestApp(t, &config.Config{})
+	got, err := a.readDOCX(writeTestDOCX(t, document, numbering))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"0) нулевой;",
+		"а) первый;",
+		"б) второй.",
+		"0. Нулевой пункт.",
+		"1. Первый пункт.",
+	}, "\n\n") + "\n"
+	if got != want {
+		t.Errorf("readDOCX:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// writeTestDOCX собирает .docx из document.xml, numbering.xml и общих тестовых стилей
+func writeTestDOCX(t *testing.T, document, numbering string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "doc.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"word/document.xml":  document,
+		"word/styles.xml":    docxTestStyles,
+		"word/numbering.xml": numbering,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+const (
+	// EncodingAuto — кодировка определяется по BOM и содержимому
+	EncodingAuto = "auto"
+	encodingUTF8 = "utf-8"
+
+	// maxBadRuneRatio — если после декодирования битых и управляющих символов больше, файл не принимается
+	maxBadRuneRatio = 0.1
+)
+
+// textEncodings — поддерживаемые кодировки текстовых файлов (TEXT_ENCODING, --encoding)
+var textEncodings = map[string]encoding.Encoding{
+	"windows-1251": charmap.Windows1251,
+	"cp1251":       charmap.Windows1251,
+	"koi8-r":       charmap.KOI8R,
+	"ibm866":       charmap.CodePage866,
+	"cp866":        charmap.CodePage866,
+	"utf-16le":     xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM),
+	"utf-16be":     xunicode.UTF16(xunicode.BigEndian, xunicode.UseBOM),
+}
+
+// legacyCyrillic — однобайтовые кодировки, из которых выбирается наиболее правдоподобная (выгрузки 1С, Windows, DOS)
+var legacyCyrillic = []string{"windows-1251", "koi8-r", "ibm866"}
+
+// frequentRussian — самые частые строчные буквы русского текста: по их доле выбирается кодировка
+const frequentRussian = "оеаинтсрвлкмдпуяыь"
+
+// decodeText переводит содержимое текстового файла в UTF-8. name — кодировка из настроек или auto:
+// BOM (UTF-8, UTF-16), UTF-16 без BOM, валидный UTF-8, иначе windows-1251, KOI8-R или CP866 —
+// та, в которой получается больше всего обычных строчных русских букв.
+// Возвращает текст и имя кодировки; ошибка — если текст после декодирования в основном из битых символов
+func decodeText(data []byte, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = EncodingAuto
+	}
+
+	var text string
+	switch name {
+	case EncodingAuto:
+		text, name = detectEncoding(data)
+	case encodingUTF8, "utf8":
+		name = encodingUTF8
+		text = string(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")))
+	default:
+		enc, ok := textEncodings[name]
+		if !ok {
+			return "", "", fmt.Errorf("unknown text encoding: %s (available: auto, utf-8, windows-1251, koi8-r, ibm866, utf-16le, utf-16be)", name)
+		}
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+		text = string(decoded)
+	}
+
+	if ratio := badRuneRatio(text); ratio > maxBadRuneRatio {
+		return "", "", fmt.Errorf("text decoded as %s is mostly invalid (%.0f%% broken or control characters): set TEXT_ENCODING or --encoding",
+			name, ratio*100)
+	}
+	return text, name, nil
+}
+
+// detectEncoding определяет кодировку по BOM и содержимому
+func detectEncoding(data []byte) (string, string) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")):
+		return string(data[3:]), encodingUTF8
+	case bytes.HasPrefix(data, []byte("\xFF\xFE")):
+		return decodeWith(data, "utf-16le"), "utf-16le"
+	case bytes.HasPrefix(data, []byte("\xFE\xFF")):
+		return decodeWith(data, "utf-16be"), "utf-16be"
+	}
+
+	// UTF-16 без BOM проверяется раньше UTF-8: кириллица в UTF-16LE ("П" — 1F 04) состоит из байтов < 0x80
+	// и проходит utf8.Valid как управляющие символы. Каждый второй байт ASCII-текста и кириллицы — 0x00 или 0x04
+	if name, ok := sniffUTF16(data); ok {
+		return decodeWith(data, name), name
+	}
+	if utf8.Valid(data) {
+		return string(data), encodingUTF8
+	}
+
+	best, bestScore := "", -1
+	var bestText string
+	for _, name := range legacyCyrillic {
+		text := decodeWith(data, name)
+		if score := russianScore(text); score > bestScore {
+			best, bestScore, bestText = name, score, text
+		}
+	}
+	return bestText, best
+}
+
+func decodeWith(data []byte, name string) string {
+	decoded, err := textEncodings[name].NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// sniffUTF16 ищет UTF-16 без BOM по нулевым старшим байтам
+func sniffUTF16(data []byte) (string, bool) {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return "", false
+	}
+	var evenZero, oddZero int
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 || data[i] == 0x04 {
+			evenZero++
+		}
+		if data[i+1] == 0 || data[i+1] == 0x04 {
+			oddZero++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case oddZero*10 > pairs*8:
+		return "utf-16le", true
+	case evenZero*10 > pairs*8:
+		return "utf-16be", true
+	}
+	return "", false
+}
+
+// russianScore — число частых строчных русских букв: у неверно выбранной кодировки
+// вместо них получаются заглавные буквы, псевдографика и знаки
+func russianScore(text string) int {
+	score := 0
+	for _, r := range text {
+		if strings.ContainsRune(frequentRussian, r) {
+			score++
+		}
+	}
+	return score
+}
+
+// badRuneRatio — доля битых (U+FFFD) и управляющих символов среди непробельных
+func badRuneRatio(text string) float64 {
+	var total, bad int
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if r == utf8.RuneError || unicode.IsControl(r) || unicode.Is(unicode.Co, r) {
+			bad++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(bad) / float64(total)
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+const encodingSample = "Статья 1. Цели и задачи трудового законодательства\nОсновными задачами являются создание условий."
+
+func TestDecodeTextDetectsEncoding(t *testing.T) {
+	encode := func(t *testing.T, enc *encoding.Encoder) []byte {
+		data, err := enc.Bytes([]byte(encodingSample))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+		want string
+	}{
+		{"utf-8", func(*testing.T) []byte { return []byte(encodingSample) }, encodingUTF8},
+		{"utf-16le without BOM", func(t *testing.T) []byte {
+			return encode(t, xunicode.UTF16(xunicode.LittleEndian, xunicode.IgnoreBOM).NewEncoder())
+		}, "utf-16le"},
+		{"utf-16be without BOM", func(t *testing.T) []byte {
+			return encode(t, xunicode.UTF16(xunicode.BigEndian, xunicode.IgnoreBOM).NewEncoder())
+		}, "utf-16be"},
+		{"windows-1251", func(t *testing.T) []byte { return encode(t, charmap.Windows1251.NewEncoder()) }, "windows-1251"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, name, err := decodeText(tt.data(t), EncodingAuto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.want {
+				t.Errorf("detected %s, want %s", name, tt.want)
+			}
+			if text != encodingSample {
+				t.Errorf("decoded text %q, want %q", text, encodingSample)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"console_rag/internal/chunker"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// htmlMaxTitleRunes — абзац длиннее не считается заголовком статьи
+const htmlMaxTitleRunes = 300
+
+var (
+	// Служебные блоки страниц правовых порталов: меню, хлебные крошки, боковые панели, баннеры
+	reHTMLBoilerplate = regexp.MustCompile(`(?i)(?:^|[-_\s])(?:nav|navbar|navigation|menu|breadcrumbs?|sidebar|footer|banner|cookies?|share|social|advert|ads)(?:$|[-_\s])`)
+	// Контейнеры статей: <div class="article">, <div class="statya">
+	reHTMLArticleClass = regexp.MustCompile(`(?i)(?:^|[-_\s])(?:article|statya)(?:$|[-_\s])`)
+)
+
+// htmlSkipped — элементы, которые никогда не содержат текст документа
+var htmlSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Aside: true, atom.Form: true, atom.Button: true, atom.Select: true,
+	atom.Iframe: true, atom.Svg: true, atom.Menu: true, atom.Dialog: true,
+}
+
+// htmlSkippedRoles — ARIA-роли служебных блоков
+var htmlSkippedRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true, "search": true, "menu": true,
+}
+
+// htmlConverter переводит HTML в Markdown: h1–h6 и заголовки статей → "#", списки → "-"/"1.", таблицы → GFM.
+// Навигация, скрипты и колонтитулы страницы отбрасываются
+type htmlConverter struct {
+	blocks       []string
+	inline       strings.Builder
+	articleDepth int  // вложенность <article> и <main>: внутри них <header> — заголовок статьи, а не шапка сайта
+	articleTitle bool // первый абзац контейнера статьи становится заголовком
+}
+
+// readHTML извлекает текст сохранённой HTML-страницы в виде Markdown
+func (a *App) readHTML(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// Кодировка — из BOM, <meta charset> или по содержимому (сохранённые страницы часто в windows-1251)
+	r, err := charset.NewReader(f, "text/html")
+	if err != nil {
+		return "", fmt.Errorf("failed to detect HTML encoding: %w", err)
+	}
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	// Если страница размечена <main>, остальное — обвязка портала
+	root := findHTMLElement(doc, atom.Main)
+	if root == nil {
+		root = doc
+	}
+
+	c := &htmlConverter{}
+	c.walk(root)
+	c.flush()
+
+	result := strings.Join(c.blocks, "\n\n")
+	if strings.TrimSpace(result) == "" {
+		return "", fmt.Errorf("no text extracted from HTML")
+	}
+	return result + "\n", nil
+}
+
+func (c *htmlConverter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.inline.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			c.walk(child)
+		}
+		return
+	}
+
+	if c.skipped(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		c.flush()
+		if text := htmlText(n); text != "" {
+			level := int(n.Data[1] - '0')
+			c.blocks = append(c.blocks, strings.Repeat("#", level)+" "+text)
+			c.articleTitle = false
+		}
+		return
+	case atom.Br:
+		c.inline.WriteString("\n")
+		return
+	case atom.Ul, atom.Ol:
+		c.flush()
+		if list := htmlList(n, 0); list != "" {
+			c.blocks = append(c.blocks, list)
+		}
+		return
+	case atom.Table:
+		c.flush()
+		if table := tableMarkdown(htmlTableRows(n)); table != "" {
+			c.blocks = append(c.blocks, table)
+		}
+		return
+	}
+
+	if !isHTMLBlock(n) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			c.walk(child)
+		}
+		return
+	}
+
+	c.flush()
+	container := n.DataAtom == atom.Article || n.DataAtom == atom.Main
+	if container {
+		c.articleDepth++
+	}
+	if n.DataAtom == atom.Article || reHTMLArticleClass.MatchString(htmlAttr(n, "class")) {
+		c.articleTitle = true
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child)
+	}
+	c.flush()
+	if container {
+		c.articleDepth--
+	}
+}
+
+// skipped проверяет, что элемент — служебный блок страницы
+func (c *htmlConverter) skipped(n *html.Node) bool {
+	if htmlSkipped[n.DataAtom] || htmlSkippedRoles[strings.ToLower(htmlAttr(n, "role"))] {
+		return true
+	}
+	if htmlHasAttr(n, "hidden") || strings.EqualFold(htmlAttr(n, "aria-hidden"), "true") {
+		return true
+	}
+	if (n.DataAtom == atom.Header || n.DataAtom == atom.Footer) && c.articleDepth == 0 {
+		return true
+	}
+	return reHTMLBoilerplate.MatchString(htmlAttr(n, "class")) || reHTMLBoilerplate.MatchString(htmlAttr(n, "id"))
+}
+
+// flush завершает накопленный абзац
+func (c *htmlConverter) flush() {
+	text := collapseHTMLText(c.inline.String())
+	c.inline.Reset()
+	if text == "" {
+		return
+	}
+
+	level := legalHeadingLevel(text)
+	if level == 0 && c.articleTitle && utf8.RuneCountInString(text) <= htmlMaxTitleRunes && !strings.Contains(text, "\n") {
+		level = 2
+	}
+	c.articleTitle = false
+
+	if level > 0 {
+		text = strings.Join(strings.Fields(text), " ")
+		c.blocks = append(c.blocks, strings.Repeat("#", level)+" "+text)
+		return
+	}
+	c.blocks = append(c.blocks, text)
+}
+
+// htmlFormat сообщает фабрике, что заголовки кодекса или закона расставил конвертер: такую страницу
+// разбивает legal chunker, а не markdown chunker по уровням "#"
+func htmlFormat(text string) string {
+	if chunker.LooksLikeLegalAct(text) {
+		return chunker.FormatLegal
+	}
+	return chunker.FormatMarkdown
+}
+
+// legalHeadingLevel возвращает уровень заголовка для "ЧАСТЬ ПЕРВАЯ", "Раздел I.", "Глава 1.", "Статья 1.", 0 — не заголовок.
+// Длинный блок — абзац текста, даже если начинается как заголовок
+func legalHeadingLevel(text string) int {
+	if utf8.RuneCountInString(text) > htmlMaxTitleRunes {
+		return 0
+	}
+	return chunker.LegalHeadingLevel(text)
+}
+
+// htmlList выводит список с вложенными списками; пункты нумерованного списка — с учётом атрибута start
+func htmlList(list *html.Node, depth int) string {
+	number := 1
+	if start, err := strconv.Atoi(htmlAttr(list, "start")); err == nil {
+		number = start
+	}
+	indent := strings.Repeat("    ", depth)
+
+	var lines []string
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		marker := "-"
+		if list.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + "."
+			number++
+		}
+		if text := strings.Join(strings.Fields(htmlText(li)), " "); text != "" {
+			lines = append(lines, indent+marker+" "+text)
+		}
+		for child := li.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && (child.DataAtom == atom.Ul || child.DataAtom == atom.Ol) {
+				if nested := htmlList(child, depth+1); nested != "" {
+					lines = append(lines, nested)
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlTableRows собирает ячейки таблицы; colspan раскрывается пустыми ячейками
+func htmlTableRows(table *html.Node) [][]string {
+	var rows [][]string
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				visit(child)
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+						continue
+					}
+					row = append(row, htmlText(cell))
+					if span, err := strconv.Atoi(htmlAttr(cell, "colspan")); err == nil {
+						for ; span > 1; span-- {
+							row = append(row, "")
+						}
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	visit(table)
+	return rows
+}
+
+// htmlText возвращает текст элемента без служебных блоков и вложенных списков
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			switch child.Type {
+			case html.TextNode:
+				b.WriteString(child.Data)
+			case html.ElementNode:
+				if htmlSkipped[child.DataAtom] || child.DataAtom == atom.Ul || child.DataAtom == atom.Ol {
+					continue
+				}
+				if child.DataAtom == atom.Br {
+					b.WriteString("\n")
+					continue
+				}
+				if isHTMLBlock(child) {
+					b.WriteString(" ")
+				}
+				visit(child)
+				if isHTMLBlock(child) {
+					b.WriteString(" ")
+				}
+			}
+		}
+	}
+	visit(n)
+	return collapseHTMLText(b.String())
+}
+
+// collapseHTMLText схлопывает пробелы как браузер; переводы строк от <br> сохраняются
+func collapseHTMLText(text string) string {
+	lines := strings.Split(text, "\n")
+	result := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// isHTMLBlock — элементы, которые начинают новый абзац
+func isHTMLBlock(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer,
+		atom.Blockquote, atom.Pre, atom.Dl, atom.Dt, atom.Dd, atom.Li, atom.Figure, atom.Figcaption,
+		atom.Address, atom.Center, atom.Body, atom.Tr, atom.Td, atom.Th, atom.Caption, atom.Hr:
+		return true
+	}
+	return false
+}
+
+func findHTMLElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findHTMLElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func htmlHasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
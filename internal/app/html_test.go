@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"console_rag/internal/chunker"
+	"console_rag/internal/config"
+)
+
+// Страница кодекса при CHUNK_METHOD=auto должна разбиваться по статьям: markdown chunker выбрал бы "##"
+// разделов и склеивал бы соседние статьи. Явно выбранный markdown chunker не подменяется
+func TestChunkLegalHTMLPageByArticles(t *testing.T) {
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Кодекс</title></head><body>")
+	page.WriteString("<nav><a href=\"/\">Главная</a></nav><main>")
+	page.WriteString("<p>ЧАСТЬ ПЕРВАЯ</p><p>Раздел I. ОБЩИЕ ПОЛОЖЕНИЯ</p>")
+	article := 0
+	for chapter := 1; chapter <= 3; chapter++ {
+		fmt.Fprintf(&page, "<p>Глава %d. ГЛАВА НОМЕР %d</p>", chapter, chapter)
+		for i := 0; i < 3; i++ {
+			article++
+			fmt.Fprintf(&page, "<p>Статья %d. Предмет статьи %d</p>", article, article)
+			for part := 1; part <= 3; part++ {
+				fmt.Fprintf(&page, "<p>%d. Положение части %d статьи %d распространяется на работников и работодателей. "+
+					"Работодатель обязан соблюдать требования статьи %d в полном объёме.</p>", part, part, article, article)
+			}
+		}
+	}
+	page.WriteString("</main><footer>© Портал</footer></body></html>")
+
+	path := filepath.Join(t.TempDir(), "codex.html")
+	if err := os.WriteFile(path, []byte(page.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestApp(t, &config.Config{})
+	doc, err := a.readFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Format != chunker.FormatLegal {
+		t.Fatalf("format %q, want %q", doc.Format, chunker.FormatLegal)
+	}
+
+	t.Run("auto", func(t *testing.T) {
+		a.cfg.ChunkMethod = chunker.MethodAuto
+		chunks, name := chunkWithConfig(t, a, doc, path)
+		if name != "legal" {
+			t.Fatalf("chunked by %s, want legal", name)
+		}
+		if len(chunks) != article {
+			t.Errorf("got %d chunks, want one per article (%d)", len(chunks), article)
+		}
+		for _, chunk := range chunks {
+			if n := strings.Count(chunk.Text, "Статья "); n != 1 {
+				t.Errorf("%s: %d article headings in one chunk", chunk.Section, n)
+			}
+			if strings.Contains(chunk.Text, "Главная") || strings.Contains(chunk.Text, "Портал") {
+				t.Errorf("%s: page navigation left in text", chunk.Section)
+			}
+		}
+	})
+
+	t.Run("explicit markdown", func(t *testing.T) {
+		a.cfg.ChunkMethod = "markdown"
+		if _, name := chunkWithConfig(t, a, doc, path); name != "markdown" {
+			t.Errorf("chunked by %s, want markdown", name)
+		}
+	})
+}
+
+// chunkWithConfig разбивает документ с текущими параметрами chunking приложения
+func chunkWithConfig(t *testing.T, a *App, doc Document, path string) ([]chunker.Chunk, string) {
+	t.Helper()
+	chunkerConfig, err := newChunkerConfig(a.cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, name, err := a.chunkFile(chunker.NewFactory(chunkerConfig), doc, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chunks, name
+}
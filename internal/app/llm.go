@@ -6,43 +6,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"regexp"
 	"strings"
-	"sync"
 	"time"
-	"unicode"
-	"unicode/utf8"
 
-	tiktoken "github.com/pkoukk/tiktoken-go"
+	"console_rag/internal/chunker"
+
 	"google.golang.org/genai"
 )
 
 // Кэшированные объекты для производительности
 var (
-	reWhitespace = regexp.MustCompile(`\s+`)
-	tiktokenOnce sync.Once
-	tiktokenEnc  *tiktoken.Tiktoken
+	reInlineSpace = regexp.MustCompile(`[ \t]+`)
+	reLineEdge    = regexp.MustCompile(`[ \t]*\n[ \t]*`)
+	reBlankLines  = regexp.MustCompile(`\n{3,}`)
 )
 
-// countTokens точно подсчитывает количество токенов в тексте
-// Использует cl100k_base encoding (для GPT-4, Qwen и совместимых моделей)
+// countTokens точно подсчитывает количество токенов в тексте (cl100k_base)
 func countTokens(text string) int {
-	tiktokenOnce.Do(func() {
-		enc, err := tiktoken.GetEncoding("cl100k_base")
-		if err != nil {
-			// Fallback будет использоваться всегда
-			return
-		}
-		tiktokenEnc = enc
-	})
-	if tiktokenEnc == nil {
-		// Fallback на консервативную оценку для русского текста
-		return len(text) / 2
-	}
-	tokens := tiktokenEnc.Encode(text, nil, nil)
-	return len(tokens)
+	return chunker.CountTokens(text)
 }
 
 // queryLLM роутер для выбора провайдера LLM (с retry)
@@ -183,30 +166,14 @@ func (a *App) queryGemini(ctx context.Context, prompt string) (string, error) {
 	return strings.TrimSpace(responseText.String()), nil
 }
 
-// cleanContentForPrompt - финальная очистка для промпта
+// cleanContentForPrompt - финальная очистка для промпта: пробелы и табуляции внутри строки
+// схлопываются, серии пустых строк сводятся к одной. Переводы строк сохраняются — по ним LLM
+// различает пункты и части статьи. Содержательная нормализация уже выполнена при индексации (NORMALIZE_REFERENCE)
 func cleanContentForPrompt(content string) string {
-	content = strings.TrimSpace(content)
-
-	// Убрать обрезанные слова в начале
-	if len(content) > 0 {
-		r, _ := utf8.DecodeRuneInString(content)
-		if unicode.IsLower(r) {
-			removed := strings.Fields(content)[0]
-			parts := strings.Fields(content)
-			if len(parts) > 1 {
-				content = strings.Join(parts[1:], " ")
-				log.Printf("🔧 cleanContentForPrompt: removed leading word %q (starts with lowercase)", removed)
-			}
-		}
-	}
-
-	// Убрать обрезанные слова в конце
-	content = strings.TrimRight(content, " -,")
-
-	// Множественные пробелы → один пробел
-	content = reWhitespace.ReplaceAllString(content, " ")
-
-	return content
+	content = reInlineSpace.ReplaceAllString(content, " ")
+	content = reLineEdge.ReplaceAllString(content, "\n")
+	content = reBlankLines.ReplaceAllString(content, "\n\n")
+	return strings.TrimSpace(content)
 }
 
 // buildAnalysisPrompt формирует промпт с контролем размера для gemma3
@@ -242,7 +209,12 @@ func (a *App) buildAnalysisPrompt(
 			continue
 		}
 
-		buf.WriteString(fmt.Sprintf("%d. %s\n", addedCount+1, cleanContent))
+		// В корпусе из нескольких документов LLM должна знать, из какого акта норма
+		if len(a.metadata.Files) > 1 {
+			buf.WriteString(fmt.Sprintf("%d. [%s] %s\n", addedCount+1, documentName(result), cleanContent))
+		} else {
+			buf.WriteString(fmt.Sprintf("%d. %s\n", addedCount+1, cleanContent))
+		}
 		addedCount++
 	}
 
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"console_rag/internal/normalize"
+
+	"github.com/philippgille/chromem-go"
+)
+
+// Manifest — параметры, с которыми построен индекс. Индекс, построенный другой embedding-моделью
+// или с другим разбиением, несовместим с текущей конфигурацией: векторы из разных пространств
+// нельзя сравнивать, а чанки устарели
+type Manifest struct {
+	EmbeddingModel string            `json:"embedding_model"`
+	Dimension      int               `json:"dimension"`
+	Chunking       map[string]string `json:"chunking"`
+	Normalizer     string            `json:"normalizer"`  // версия правил и шаги NORMALIZE_REFERENCE
+	SourceHash     string            `json:"source_hash"` // hash абсолютного пути REFERENCE_DOC
+}
+
+// sourceHash — короткий hash абсолютного пути эталона: различает одноимённые документы
+// из разных директорий в имени файла БД и в манифесте
+func sourceHash(reference string) string {
+	if abs, err := filepath.Abs(reference); err == nil {
+		reference = abs
+	}
+	hash := sha256.Sum256([]byte(reference))
+	return fmt.Sprintf("%x", hash[:4])
+}
+
+// currentManifest собирает манифест текущей конфигурации. Размерность вектора не заполняется:
+// у существующего индекса она берётся из сохранённого манифеста и сверяется с первым вычисленным
+// вектором (checkedEmbedding), у нового — пробным запросом к embedding API (probeDimension)
+func (a *App) currentManifest() Manifest {
+	cfg := a.cfg
+	return Manifest{
+		EmbeddingModel: cfg.LlmEmbed.Model,
+		Chunking: map[string]string{
+			"method":             cfg.ChunkMethod,
+			"size":               strconv.Itoa(cfg.ChunkSize),
+			"overlap":            strconv.Itoa(cfg.ChunkOverlap),
+			"min_size":           strconv.Itoa(cfg.ChunkMinSize),
+			"unit":               cfg.ChunkUnit,
+			"prepend_path":       strconv.FormatBool(cfg.ChunkPath),
+			"fallback":           strings.Join(cfg.ChunkFallback, ","),
+			"md_min_level":       strconv.Itoa(cfg.MarkdownMinLevel),
+			"md_max_level":       strconv.Itoa(cfg.MarkdownMaxLevel),
+			"md_min_headings":    formatLevels(cfg.MarkdownMinHeadings),
+			"md_strategy":        cfg.MarkdownStrategy,
+			"semantic_threshold": strconv.FormatFloat(float64(cfg.SemanticThreshold), 'g', -1, 32),
+			"extract_amendments": strconv.FormatBool(cfg.ExtractAmendments),
+			"dedup_threshold":    strconv.FormatFloat(cfg.DedupThreshold, 'g', -1, 64),
+			"parent_child":       strconv.FormatBool(cfg.ParentChild),
+			"text_encoding":      cfg.TextEncoding,
+			"reader_command":     cfg.ReaderCommand,
+		},
+		Normalizer: fmt.Sprintf("v%d:%s", normalize.Version, strings.Join(a.referenceNormalize.Names(), ",")),
+		SourceHash: sourceHash(cfg.ReferenceDoc),
+	}
+}
+
+// probeDimension узнаёт размерность вектора пробным запросом к embedding API
+func (a *App) probeDimension(ctx context.Context) (int, error) {
+	probe, err := a.embeddingFunc(ctx, "manifest")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query embedding dimension: %w", err)
+	}
+	return len(probe), nil
+}
+
+// checkedEmbedding сверяет размерность вычисленных векторов с манифестом индекса: если под тем же
+// LLM_EMBED_MODEL сервер отдаёт другую модель, новые векторы несравнимы с сохранёнными.
+// Проверка срабатывает на первом векторе, посчитанном после загрузки манифеста (новый чанк или запрос)
+func (a *App) checkedEmbedding(embed chromem.EmbeddingFunc) chromem.EmbeddingFunc {
+	var checked atomic.Bool
+	return func(ctx context.Context, text string) ([]float32, error) {
+		vector, err := embed(ctx, text)
+		if err != nil || checked.Load() {
+			return vector, err
+		}
+		if a.metadata == nil || a.metadata.Manifest == nil || a.metadata.Manifest.Dimension == 0 {
+			return vector, nil
+		}
+		if want := a.metadata.Manifest.Dimension; len(vector) != want {
+			return nil, fmt.Errorf("embedding dimension %d differs from the index (%d): the model behind %q has changed, delete the index or set INDEX_REBUILD=true",
+				len(vector), want, a.cfg.LlmEmbed.Model)
+		}
+		checked.Store(true)
+		return vector, nil
+	}
+}
+
+// diff перечисляет расхождения сохранённого манифеста с текущим: "CHUNK_SIZE: 1000 → 800"
+func (m *Manifest) diff(current Manifest) []string {
+	if m == nil {
+		return []string{"index has no manifest (built by an older version)"}
+	}
+
+	var diffs []string
+	add := func(name, was, now string) {
+		if was != now {
+			diffs = append(diffs, fmt.Sprintf("%s: %q → %q", name, was, now))
+		}
+	}
+	add("embedding model", m.EmbeddingModel, current.EmbeddingModel)
+	add("normalizer", m.Normalizer, current.Normalizer)
+	add("reference path", m.SourceHash, current.SourceHash)
+
+	keys := make(map[string]bool)
+	for key := range m.Chunking {
+		keys[key] = true
+	}
+	for key := range current.Chunking {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		add("chunking "+key, m.Chunking[key], current.Chunking[key])
+	}
+	return diffs
+}
+
+// formatLevels записывает MD_MIN_HEADINGS в стабильном порядке: "1:3,2:3"
+func formatLevels(levels map[int]int) string {
+	keys := make([]int, 0, len(levels))
+	for level := range levels {
+		keys = append(keys, level)
+	}
+	sort.Ints(keys)
+
+	parts := make([]string, len(keys))
+	for i, level := range keys {
+		parts[i] = fmt.Sprintf("%d:%d", level, levels[level])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"console_rag/internal/config"
+	"console_rag/internal/normalize"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	a, _ := newIndexTestApp(t, t.TempDir())
+	manifest := a.currentManifest()
+	manifest.Dimension = 768
+	a.metadata.Manifest = &manifest
+	if err := a.saveMetadata(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &App{fileMetadata: a.fileMetadata}
+	if err := loaded.loadMetadata(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.metadata.Manifest, &manifest) {
+		t.Errorf("loaded %+v\nwant %+v", loaded.metadata.Manifest, manifest)
+	}
+	if diffs := loaded.metadata.Manifest.diff(a.currentManifest()); len(diffs) != 0 {
+		t.Errorf("unexpected diffs after round trip: %v", diffs)
+	}
+}
+
+func TestManifestDiff(t *testing.T) {
+	dir := t.TempDir()
+	a, _ := newIndexTestApp(t, dir)
+	stored := a.currentManifest()
+
+	tests := []struct {
+		name   string
+		change func(a *App)
+		want   string
+	}{
+		{"chunk size", func(a *App) { a.cfg.ChunkSize = 800 }, `chunking size: "1000" → "800"`},
+		{"chunk method", func(a *App) { a.cfg.ChunkMethod = "legal" }, "chunking method"},
+		{"dedup threshold", func(a *App) { a.cfg.DedupThreshold = 0.9 }, "chunking dedup_threshold"},
+		{"embedding model", func(a *App) { a.cfg.LlmEmbed.Model = "other" }, "embedding model"},
+		{"normalization steps", func(a *App) {
+			a.referenceNormalize, _ = normalize.New([]string{"whitespace", "yo"}, nil, nil)
+		}, "normalizer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newIndexTestApp(t, dir)
+			tt.change(a)
+			diffs := stored.diff(a.currentManifest())
+			if len(diffs) != 1 || !strings.Contains(diffs[0], tt.want) {
+				t.Errorf("diffs %q, want one with %q", diffs, tt.want)
+			}
+		})
+	}
+
+	// Правила нормализации другой версии — тот же список шагов, но индекс устарел
+	old := stored
+	old.Normalizer = strings.Replace(stored.Normalizer, fmt.Sprintf("v%d:", normalize.Version), fmt.Sprintf("v%d:", normalize.Version-1), 1)
+	if diffs := old.diff(stored); len(diffs) != 1 || !strings.Contains(diffs[0], "normalizer") {
+		t.Errorf("normalizer version change: diffs %q", diffs)
+	}
+
+	var missing *Manifest
+	if diffs := missing.diff(stored); len(diffs) != 1 {
+		t.Errorf("index without a manifest: diffs %q", diffs)
+	}
+}
+
+// Init перестраивает индекс, построенный с другими параметрами chunking или старыми правилами нормализации,
+// а при INDEX_REBUILD=false останавливается с ошибкой
+func TestInitRebuildsIndexOnManifestChange(t *testing.T) {
+	dir := t.TempDir()
+	content := testDocument("Отпуск составляет 28 календарных дней.", "Зарплата выплачивается дважды в месяц.",
+		"Рабочая неделя — сорок часов.")
+	if err := os.WriteFile(filepath.Join(dir, "rules.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data := t.TempDir()
+
+	// open запускает Init и возвращает число вычисленных векторов: при перестройке индекса
+	// все чанки векторизуются заново, без неё — ни один
+	open := func(change func(cfg *config.Config)) (*App, int64, error) {
+		a, embedded := newIndexTestApp(t, dir)
+		a.cfg.LlmMain = config.Llm{Type: "openai", URL: "http://llm.local", Model: "main"}
+		a.cfg.LlmEmbed = config.Llm{Type: "openai", URL: "http://embed.local", Model: "embed"}
+		a.cfg.IndexRebuild = true
+		a.fileDB = filepath.Join(data, "index.gob")
+		a.fileMetadata = filepath.Join(data, "index_metadata.json")
+		if change != nil {
+			change(a.cfg)
+		}
+		err := a.Init(context.Background())
+		return a, embedded.Load(), err
+	}
+
+	a, _, err := open(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built := a.metadata.Manifest
+	if built == nil || built.Dimension != 3 {
+		t.Fatalf("manifest %+v, want dimension 3", built)
+	}
+
+	t.Run("same settings", func(t *testing.T) {
+		a, embedded, err := open(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if embedded != 0 {
+			t.Errorf("computed %d embeddings, want the index reused", embedded)
+		}
+		if !reflect.DeepEqual(a.metadata.Manifest, built) {
+			t.Errorf("manifest changed: %+v", a.metadata.Manifest)
+		}
+	})
+
+	t.Run("rebuild disabled", func(t *testing.T) {
+		_, _, err := open(func(cfg *config.Config) { cfg.ChunkSize = 500; cfg.IndexRebuild = false })
+		if err == nil || !strings.Contains(err.Error(), "chunking size") {
+			t.Fatalf("error %v, want a chunking size mismatch", err)
+		}
+	})
+
+	t.Run("chunking changed", func(t *testing.T) {
+		a, embedded, err := open(func(cfg *config.Config) { cfg.ChunkSize = 500 })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if embedded == 0 {
+			t.Error("index was not rebuilt")
+		}
+		if got := a.metadata.Manifest.Chunking["size"]; got != "500" {
+			t.Errorf("manifest size %s, want 500", got)
+		}
+	})
+
+	t.Run("normalizer version changed", func(t *testing.T) {
+		a, _, err := open(func(cfg *config.Config) { cfg.ChunkSize = 500 })
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.metadata.Manifest.Normalizer = "v0:whitespace"
+		if err := a.saveMetadata(); err != nil {
+			t.Fatal(err)
+		}
+		a, embedded, err := open(func(cfg *config.Config) { cfg.ChunkSize = 500 })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if embedded == 0 {
+			t.Error("index was not rebuilt")
+		}
+		if want := fmt.Sprintf("v%d:whitespace", normalize.Version); a.metadata.Manifest.Normalizer != want {
+			t.Errorf("manifest normalizer %s, want %s", a.metadata.Manifest.Normalizer, want)
+		}
+	})
+}
@@ -0,0 +1,471 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"console_rag/internal/chunker"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// pdfEdgeLines — сколько строк сверху и снизу страницы проверяется на колонтитулы
+	pdfEdgeLines = 3
+	// pdfDefaultFontSize — размер шрифта, если PDF его не сообщает
+	pdfDefaultFontSize = 10
+	// pdfMinColumnRows — сколько строк должно иметь общий промежуток, чтобы страница считалась двухколоночной
+	pdfMinColumnRows = 3
+)
+
+var (
+	// Номер страницы в колонтитуле: "3", "- 3 -", "Страница 3 из 12", "стр. 3", "Page 3 of 12"
+	rePDFPageNumber = regexp.MustCompile(`(?i)^[-–—\s]*(?:страница|стр\.?|page)?\s*\d{1,4}(?:\s*(?:из|of|/)\s*\d{1,4})?[-–—\s]*$`)
+	// Начало нового абзаца независимо от геометрии: пункт списка (структурные заголовки — chunker.IsLegalHeading)
+	rePDFParagraphStart = regexp.MustCompile(`^(?:\d+(?:\.\d+)*[.)]\s|[а-яёa-z]\)\s|[-–—•]\s)`)
+	rePDFSentenceEnd    = regexp.MustCompile(`[.:;!?]$`)
+)
+
+// pdfLine — строка страницы, собранная из символов по координатам
+type pdfLine struct {
+	text        string
+	left, right float64 // границы строки по X
+	y           float64 // базовая линия, растёт снизу вверх
+	fontSize    float64 // 0 — геометрия неизвестна (текст получен GetPlainText)
+	column      int     // 0 — во всю ширину страницы, 1 — левая колонка, 2 — правая
+}
+
+// readPDF извлекает текст PDF с учётом вёрстки: строки собираются из координат символов, повторяющиеся
+// на страницах колонтитулы и номера страниц удаляются, переносы слов склеиваются, абзацы восстанавливаются
+// по межстрочным интервалам и отступам. Страницы разделяются chunker.PageBreak — по нему в отчёте
+// указываются страницы эталона
+func (a *App) readPDF(path string) (string, error) {
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer f.Close()
+
+	if err := pdfEncryptionError(r); err != nil {
+		return "", err
+	}
+
+	totalPages := r.NumPage()
+	pages := make([][]pdfLine, totalPages)
+	for pageNum := 1; pageNum <= totalPages; pageNum++ {
+		page := r.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+
+		lines, err := pdfPageLines(page)
+		if err != nil {
+			a.logger.Errorf("Warning: failed to extract text from page %d: %v", pageNum, err)
+			continue
+		}
+		pages[pageNum-1] = lines
+	}
+
+	if removed := removeRunningLines(pages); removed > 0 {
+		a.logger.Infof("🧾 Removed %d header/footer lines repeated across PDF pages", removed)
+	}
+
+	var textBuilder strings.Builder
+	for i, lines := range pages {
+		// Один разделитель на каждую страницу после первой, даже пустую, чтобы номера страниц в отчёте не съезжали
+		if i > 0 {
+			textBuilder.WriteString(chunker.PageBreak)
+		}
+		textBuilder.WriteString(pdfParagraphs(lines))
+		textBuilder.WriteString("\n\n")
+	}
+
+	result := textBuilder.String()
+	if strings.TrimSpace(strings.ReplaceAll(result, chunker.PageBreak, "")) == "" {
+		return "", fmt.Errorf("no text extracted from PDF")
+	}
+
+	return result, nil
+}
+
+// pdfEncryptionError отклоняет PDF, которые ledongthuc/pdf не может расшифровать. Библиотека берёт ключ RC4
+// объекта целиком из MD5 (16 байт), а по PDF 32000-1 §7.6.2 он обрезается до n/8+5 байт: при ключе файла
+// короче 88 бит потоки расшифровываются в мусор и распаковка падает с "zlib: invalid header" на каждой странице.
+// AES (V=4) и 128-битный RC4 читаются правильно
+func pdfEncryptionError(r *pdf.Reader) error {
+	encrypt := r.Trailer().Key("Encrypt")
+	if encrypt.IsNull() || encrypt.Key("V").Int64() == 4 {
+		return nil
+	}
+	bits := encrypt.Key("Length").Int64()
+	if bits == 0 || encrypt.Key("V").Int64() == 1 {
+		bits = 40
+	}
+	if bits/8+5 >= 16 {
+		return nil
+	}
+	return fmt.Errorf("PDF is encrypted with %d-bit RC4, which the built-in reader cannot decrypt: "+
+		"remove the protection (qpdf --decrypt in.pdf out.pdf) or convert it with READER_COMMAND (pdftotext {input} -)", bits)
+}
+
+// pdfPageLines собирает строки страницы из символов с координатами (Page.Content). GetTextByRow для этого
+// не подходит: он не знает ширину символов и не учитывает сдвиги Td. Если координат нет,
+// используется GetPlainText без геометрии
+func pdfPageLines(page pdf.Page) (lines []pdfLine, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			lines, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	var chars []pdf.Text
+	for _, t := range page.Content().Text {
+		if t.S != "" {
+			chars = append(chars, t)
+		}
+	}
+	if len(chars) == 0 {
+		return pdfPlainLines(page)
+	}
+
+	// Сверху вниз, внутри строки — в порядке вывода (символы одного Tj могут иметь одинаковый X)
+	sort.SliceStable(chars, func(i, j int) bool { return chars[i].Y > chars[j].Y })
+
+	var rows [][]pdf.Text
+	var row []pdf.Text
+	for _, c := range chars {
+		if len(row) > 0 && row[0].Y-c.Y > pdfFontSize(c)/2 {
+			rows = append(rows, row)
+			row = nil
+		}
+		row = append(row, c)
+	}
+	rows = append(rows, row)
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].X < row[j].X })
+	}
+	return pdfColumnLines(rows), nil
+}
+
+// pdfColumnLines собирает строки страницы. На двухколоночной странице строки между первой и последней,
+// которые не пересекают промежуток между колонками, идут сначала левой колонкой, потом правой
+// (базовые линии колонок могут не совпадать); строки во всю ширину (колонтитулы, заголовок над
+// колонками) остаются на своих местах
+func pdfColumnLines(rows [][]pdf.Text) []pdfLine {
+	gutter := pdfGutter(rows)
+	first, last := len(rows), -1
+	if gutter > 0 {
+		for i, row := range rows {
+			if left, right := pdfSplitRow(row, gutter); left != nil || right != nil {
+				first, last = min(first, i), i
+			}
+		}
+	}
+
+	var lines, left, right []pdfLine
+	flush := func() {
+		lines = append(append(lines, left...), right...)
+		left, right = nil, nil
+	}
+	add := func(to *[]pdfLine, chars []pdf.Text, column int) {
+		if line, ok := buildPDFLine(chars); ok {
+			line.column = column
+			*to = append(*to, line)
+		}
+	}
+	for i, row := range rows {
+		l, r := pdfSplitRow(row, gutter)
+		if i < first || i > last || l == nil && r == nil {
+			flush()
+			add(&lines, row, 0)
+			continue
+		}
+		if len(l) > 0 {
+			add(&left, l, 1)
+		}
+		if len(r) > 0 {
+			add(&right, r, 2)
+		}
+	}
+	flush()
+	return lines
+}
+
+// pdfGutter ищет промежуток между колонками: X, левее которого заканчивается текст не меньше
+// pdfMinColumnRows строк и правее начинается текст стольких же строк, а пересекает его меньше строк,
+// чем в каждой колонке (колонтитулы, заголовок над колонками). Кандидаты — чуть левее начала строки
+// или фрагмента строки после широкого зазора. 0 — колонка одна
+func pdfGutter(rows [][]pdf.Text) float64 {
+	var candidates []float64
+	for _, row := range rows {
+		for i, c := range row {
+			if i == 0 || c.X-pdfCharEnd(row[i-1]) > 2*pdfFontSize(c) {
+				candidates = append(candidates, c.X-pdfFontSize(c)/2)
+			}
+		}
+	}
+
+	best, bestRows := 0.0, 0
+	for _, x := range candidates {
+		left, right, crossing := 0, 0, 0
+		for _, row := range rows {
+			l, r := pdfSplitRow(row, x)
+			if l == nil && r == nil {
+				crossing++
+			}
+			if len(l) > 0 {
+				left++
+			}
+			if len(r) > 0 {
+				right++
+			}
+		}
+		if n := min(left, right); n >= pdfMinColumnRows && n > crossing && n > bestRows {
+			best, bestRows = x, n
+		}
+	}
+	return best
+}
+
+// pdfSplitRow делит строку по промежутку между колонками. nil, nil — строка его пересекает:
+// символ накрывает gutter или части строки по обе стороны разделяет обычный межсловный пробел
+func pdfSplitRow(row []pdf.Text, gutter float64) (left, right []pdf.Text) {
+	if gutter <= 0 {
+		return nil, nil
+	}
+	for _, c := range row {
+		switch {
+		case pdfCharEnd(c) <= gutter:
+			left = append(left, c)
+		case c.X >= gutter:
+			right = append(right, c)
+		default:
+			return nil, nil
+		}
+	}
+	if len(left) > 0 && len(right) > 0 && right[0].X-pdfCharEnd(left[len(left)-1]) <= 2*pdfFontSize(right[0]) {
+		return nil, nil
+	}
+	return left, right
+}
+
+// pdfPlainLines — запасной вариант для страниц, где не удалось получить координаты символов
+func pdfPlainLines(page pdf.Page) ([]pdfLine, error) {
+	text, err := page.GetPlainText(nil)
+	if err != nil {
+		return nil, err
+	}
+	var lines []pdfLine
+	for _, s := range strings.Split(text, "\n") {
+		if s = strings.Join(strings.Fields(s), " "); s != "" {
+			lines = append(lines, pdfLine{text: s})
+		}
+	}
+	return lines, nil
+}
+
+// buildPDFLine склеивает символы строки слева направо; пробел вставляется, если между символами есть зазор
+func buildPDFLine(row []pdf.Text) (pdfLine, bool) {
+	chars := append([]pdf.Text(nil), row...)
+	sort.SliceStable(chars, func(i, j int) bool { return chars[i].X < chars[j].X })
+
+	line := pdfLine{y: chars[0].Y}
+	var b strings.Builder
+	for i, c := range chars {
+		fontSize := pdfFontSize(c)
+		line.fontSize = max(line.fontSize, fontSize)
+
+		blank := strings.TrimSpace(c.S) == ""
+		if i > 0 && !blank {
+			prev := chars[i-1]
+			if c.X != prev.X && c.X-pdfCharEnd(prev) > fontSize/4 {
+				b.WriteString(" ")
+			}
+		}
+		if !blank && b.Len() == 0 {
+			line.left = c.X
+		}
+		if !blank {
+			line.right = pdfCharEnd(c)
+		}
+		b.WriteString(c.S)
+	}
+
+	line.text = strings.Join(strings.Fields(b.String()), " ")
+	return line, line.text != ""
+}
+
+func pdfFontSize(c pdf.Text) float64 {
+	if c.FontSize > 0 {
+		return c.FontSize
+	}
+	return pdfDefaultFontSize
+}
+
+// pdfCharEnd — правая граница символа; без ширины из шрифта — оценка в полкегля
+func pdfCharEnd(c pdf.Text) float64 {
+	if c.W > 0 {
+		return c.X + c.W
+	}
+	return c.X + pdfFontSize(c)/2*float64(utf8.RuneCountInString(c.S))
+}
+
+// removeRunningLines удаляет колонтитулы: строки у верхнего и нижнего края, которые повторяются
+// на половине страниц и более (цифры не учитываются: "Страница 3 из 12"), и одиночные номера страниц.
+// Заголовки частей, разделов, глав и статей не удаляются: без цифр "Статья 12" и "Статья 13" совпадают,
+// а в кодексе статья часто начинается с новой страницы
+func removeRunningLines(pages [][]pdfLine) int {
+	nonEmpty := 0
+	occurrences := make(map[string]int)
+	for _, lines := range pages {
+		if len(lines) == 0 {
+			continue
+		}
+		nonEmpty++
+		seen := make(map[string]bool)
+		for _, i := range pdfEdgeIndexes(len(lines)) {
+			key := pdfLineKey(lines[i].text)
+			if !seen[key] {
+				seen[key] = true
+				occurrences[key]++
+			}
+		}
+	}
+	threshold := max(2, (nonEmpty+1)/2)
+
+	removed := 0
+	for p, lines := range pages {
+		edge := make(map[int]bool)
+		for _, i := range pdfEdgeIndexes(len(lines)) {
+			edge[i] = true
+		}
+		kept := lines[:0]
+		for i, line := range lines {
+			running := occurrences[pdfLineKey(line.text)] >= threshold && !chunker.IsLegalHeading(line.text)
+			if edge[i] && (running || rePDFPageNumber.MatchString(line.text)) {
+				removed++
+				continue
+			}
+			kept = append(kept, line)
+		}
+		pages[p] = kept
+	}
+	return removed
+}
+
+// pdfEdgeIndexes возвращает индексы первых и последних pdfEdgeLines строк страницы
+func pdfEdgeIndexes(n int) []int {
+	var indexes []int
+	for i := 0; i < n; i++ {
+		if i < pdfEdgeLines || i >= n-pdfEdgeLines {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// pdfLineKey — строка без регистра и цифр, для сравнения колонтитулов разных страниц
+func pdfLineKey(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '#'
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// pdfParagraphs собирает строки страницы в абзацы. Новый абзац начинается после увеличенного интервала,
+// с красной строки, после короткой (не дотянутой до правого края колонки) строки и с пункта списка
+// или заголовка статьи. Абзац, не законченный внизу левой колонки, продолжается в правой.
+// Перенос слова через дефис в конце строки склеивается
+func pdfParagraphs(lines []pdfLine) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	// Границы текста по колонкам: короткая строка и красная строка определяются внутри своей колонки
+	type bounds struct{ left, right float64 }
+	columns := make(map[int]bounds)
+	var gaps []float64
+	for i, line := range lines {
+		b, ok := columns[line.column]
+		if !ok {
+			b = bounds{line.left, line.right}
+		}
+		columns[line.column] = bounds{min(b.left, line.left), max(b.right, line.right)}
+		if i > 0 && lines[i-1].column == line.column && lines[i-1].y > line.y {
+			gaps = append(gaps, lines[i-1].y-line.y)
+		}
+	}
+	sort.Float64s(gaps)
+	spacing := 0.0
+	if len(gaps) > 0 {
+		spacing = gaps[len(gaps)/2]
+	}
+
+	var paragraphs []string
+	var current strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			// Перенос слова всегда продолжает абзац, даже если строка с переносом короче остальных
+			if head, ok := pdfHyphenated(current.String(), line.text); ok {
+				current.Reset()
+				current.WriteString(head)
+				current.WriteString(line.text)
+				continue
+			}
+
+			newParagraph := rePDFParagraphStart.MatchString(line.text) || chunker.IsLegalHeading(line.text)
+			if line.fontSize > 0 && prev.fontSize > 0 {
+				indent := line.fontSize * 0.8
+				// Первая строка новой колонки сравнивается с левым краем колонки, а не с предыдущей строкой
+				lineLeft := prev.left
+				if line.column != prev.column {
+					lineLeft = columns[line.column].left
+				}
+				prevColumn := columns[prev.column]
+				switch {
+				case line.column == prev.column && spacing > 0 && prev.y-line.y > spacing*1.4:
+					newParagraph = true
+				case line.left > lineLeft+indent:
+					newParagraph = true
+				case prev.right < prevColumn.right-(prevColumn.right-prevColumn.left)*0.15:
+					newParagraph = true
+				}
+			} else if rePDFSentenceEnd.MatchString(prev.text) {
+				newParagraph = true
+			}
+
+			if !newParagraph {
+				current.WriteString(" ")
+				current.WriteString(line.text)
+				continue
+			}
+			paragraphs = append(paragraphs, current.String())
+			current.Reset()
+		}
+		current.WriteString(line.text)
+	}
+	paragraphs = append(paragraphs, current.String())
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// pdfHyphenated проверяет, что text заканчивается переносом слова ("возмож-"), а next его продолжает ("ность").
+// Возвращает text без знака переноса
+func pdfHyphenated(text, next string) (string, bool) {
+	last, size := utf8.DecodeLastRuneInString(text)
+	if last != '-' && last != '\u00AD' {
+		return "", false
+	}
+	beforeLast, _ := utf8.DecodeLastRuneInString(text[:len(text)-size])
+	first, _ := utf8.DecodeRuneInString(next)
+	if !unicode.IsLetter(beforeLast) || !unicode.IsLower(first) {
+		return "", false
+	}
+	return text[:len(text)-size], true
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"console_rag/internal/chunker"
+	"console_rag/internal/config"
+)
+
+// LNA_example.pdf зашифрован 40-битным RC4, который ledongthuc/pdf расшифровывает неверно:
+// вместо "zlib: invalid header" на каждой странице ожидается понятная ошибка
+func TestReadPDFRejectsWeakRC4Encryption(t *testing.T) {
+	a := newTestApp(t, &config.Config{})
+
+	_, err := a.readPDF("../../docs/LNA_example.pdf")
+	if err == nil {
+		t.Fatal("expected an error for a 40-bit RC4 encrypted PDF")
+	}
+	if !strings.Contains(err.Error(), "40-bit RC4") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Без цифр заголовки статей в начале страниц совпадают и выглядят как колонтитул
+func TestRemoveRunningLinesKeepsLegalHeadings(t *testing.T) {
+	pages := [][]pdfLine{
+		{{text: "Статья 12"}, {text: "Текст первой страницы"}},
+		{{text: "Статья 13"}, {text: "Текст второй страницы"}},
+		{{text: "Статья 14"}, {text: "Текст третьей страницы"}},
+	}
+
+	if removed := removeRunningLines(pages); removed != 0 {
+		t.Fatalf("removed %d lines, want 0", removed)
+	}
+	for i, lines := range pages {
+		if len(lines) != 2 {
+			t.Fatalf("page %d: %d lines left, want 2", i+1, len(lines))
+		}
+	}
+}
+
+// columns.pdf: две страницы в две колонки со смещёнными строками, общим колонтитулом
+// и номером страницы внизу. Абзац переносится словом "распо-рядка" и продолжается в правой колонке
+func TestReadPDFColumnsAndRunningLines(t *testing.T) {
+	a := newTestApp(t, &config.Config{})
+
+	text, err := a.readPDF("testdata/columns.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, running := range []string{"Ромашка", "Страница"} {
+		if strings.Contains(text, running) {
+			t.Errorf("running line %q left in text", running)
+		}
+	}
+	if n := strings.Count(text, chunker.PageBreak); n != 1 {
+		t.Errorf("got %d page breaks, want 1", n)
+	}
+
+	paragraphs := strings.Split(strings.ReplaceAll(text, chunker.PageBreak, ""), "\n\n")
+	for _, want := range []string{
+		"Статья 1",
+		"Общие положения",
+		"Работодатель знакомит работника с правилами внутреннего трудового распорядка до подписания " +
+			"трудового договора и хранит лист ознакомления в личном деле работника вместе с копией приказа о приеме.",
+		"Статья 2",
+		"Рабочее время",
+	} {
+		if !slices.Contains(paragraphs, want) {
+			t.Errorf("paragraph %q not found in:\n%s", want, text)
+		}
+	}
+
+	left := strings.Index(text, "Нормальная продолжительность")
+	right := strings.Index(text, "Работнику устанавливается")
+	if left < 0 || right < 0 || left > right {
+		t.Errorf("left column must precede the right one:\n%s", text)
+	}
+}
@@ -0,0 +1,341 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"console_rag/internal/chunker"
+)
+
+const (
+	// readerSniffBytes — сколько байт начала файла передаётся в Sniff
+	readerSniffBytes = 512
+	// readerCommandTimeout — предельное время работы внешнего конвертера на один файл
+	readerCommandTimeout = 5 * time.Minute
+	// readerInputPlaceholder — место пути к файлу в READER_COMMAND
+	readerInputPlaceholder = "{input}"
+)
+
+// Document — текст, извлечённый reader'ом из файла, и подсказки о его структуре
+type Document struct {
+	Text string
+	// Format — разметка текста (chunker.FormatMarkdown, chunker.FormatText, chunker.FormatLegal): по ней фабрика выбирает chunker,
+	// когда расширение файла ничего не говорит (.docx и HTML читаются в Markdown)
+	Format string
+	// Reader — имя reader'а, прочитавшего файл
+	Reader string
+	// Converted — текст получен преобразованием файла (DOCX, HTML, READER_COMMAND): строки и смещения
+	// чанков относятся к этому тексту, а не к исходному файлу
+	Converted bool
+}
+
+// DocumentReader извлекает текст из файлов одного формата. Reader, добавленный в реестр,
+// работает для эталона, проверяемого документа и файлов из интерактивного ввода одновременно
+type DocumentReader interface {
+	Name() string
+	// Extensions — расширения файлов (".pdf"), которые читает reader
+	Extensions() []string
+	// Sniff проверяет начало файла, если расширение неизвестно (например, файл без расширения)
+	Sniff(head []byte) bool
+	Read(path string) (Document, error)
+}
+
+// readerRegistry выбирает reader по расширению файла, затем по содержимому (Sniff),
+// иначе — внешний конвертер READER_COMMAND, если он задан без списка расширений
+type readerRegistry struct {
+	readers  []DocumentReader
+	fallback DocumentReader
+}
+
+// register добавляет reader. Если расширение уже занято, выбирается reader, добавленный раньше
+func (r *readerRegistry) register(reader DocumentReader) {
+	r.readers = append(r.readers, reader)
+}
+
+func (r *readerRegistry) byExtension(ext string) (DocumentReader, bool) {
+	ext = strings.ToLower(ext)
+	for _, reader := range r.readers {
+		for _, e := range reader.Extensions() {
+			if e == ext {
+				return reader, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// lookup находит reader для файла
+func (r *readerRegistry) lookup(path string) (DocumentReader, error) {
+	reader, err := r.find(path)
+	if err != nil || reader != nil {
+		return reader, err
+	}
+	if r.fallback != nil {
+		return r.fallback, nil
+	}
+	return nil, r.unsupported(path)
+}
+
+// match находит reader по расширению или содержимому, без внешнего конвертера-fallback'а: так выбираются
+// файлы при обходе директории, чтобы .gitignore, картинки и прочие файлы не уходили в конвертер
+func (r *readerRegistry) match(path string) (DocumentReader, error) {
+	reader, err := r.find(path)
+	if err == nil && reader == nil {
+		err = r.unsupported(path)
+	}
+	return reader, err
+}
+
+// find выбирает reader по расширению, затем по содержимому; nil без ошибки — формат не распознан
+func (r *readerRegistry) find(path string) (DocumentReader, error) {
+	if reader, ok := r.byExtension(filepath.Ext(path)); ok {
+		return reader, nil
+	}
+
+	head, err := readHead(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, reader := range r.readers {
+		if reader.Sniff(head) {
+			return reader, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *readerRegistry) unsupported(path string) error {
+	return fmt.Errorf("unsupported file format: %s (supported: %s)", strings.ToLower(filepath.Ext(path)), strings.Join(r.extensions(), ", "))
+}
+
+// extensions возвращает все расширения, для которых есть reader
+func (r *readerRegistry) extensions() []string {
+	seen := make(map[string]bool)
+	var exts []string
+	for _, reader := range r.readers {
+		for _, ext := range reader.Extensions() {
+			if !seen[ext] {
+				seen[ext] = true
+				exts = append(exts, ext)
+			}
+		}
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, readerSniffBytes)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+// fileReader — встроенный reader: функция чтения и формат её результата
+type fileReader struct {
+	name       string
+	extensions []string
+	sniff      func(head []byte) bool
+	format     string
+	converted  bool // текст — результат преобразования, а не содержимое файла
+	// formatOf уточняет формат по прочитанному тексту (необязательно)
+	formatOf func(text string) string
+	read     func(path string) (string, error)
+}
+
+func (r *fileReader) Name() string         { return r.name }
+func (r *fileReader) Extensions() []string { return r.extensions }
+
+func (r *fileReader) Sniff(head []byte) bool {
+	return r.sniff != nil && r.sniff(head)
+}
+
+func (r *fileReader) Read(path string) (Document, error) {
+	text, err := r.read(path)
+	if err != nil {
+		return Document{}, err
+	}
+	format := r.format
+	if r.formatOf != nil {
+		format = r.formatOf(text)
+	}
+	return Document{Text: text, Format: format, Reader: r.name, Converted: r.converted}, nil
+}
+
+// newReaders собирает реестр: встроенные форматы и внешний конвертер из READER_COMMAND
+func (a *App) newReaders() (*readerRegistry, error) {
+	r := &readerRegistry{}
+
+	// Внешний конвертер со списком расширений регистрируется первым: так им можно заменить и встроенный reader
+	if strings.TrimSpace(a.cfg.ReaderCommand) != "" {
+		cmd, err := newCommandReader(a.cfg.ReaderCommand, a.cfg.ReaderCommandExtensions, a.cfg.ReaderCommandFormat)
+		if err != nil {
+			return nil, err
+		}
+		if len(cmd.extensions) == 0 {
+			r.fallback = cmd
+		} else {
+			r.register(cmd)
+		}
+	}
+
+	r.register(&fileReader{
+		name:       "markdown",
+		extensions: []string{".md", ".markdown"},
+		format:     chunker.FormatMarkdown,
+		read:       a.readText,
+	})
+	r.register(&fileReader{
+		name:       "text",
+		extensions: []string{".txt", ".text"},
+		format:     chunker.FormatText,
+		read:       a.readText,
+	})
+	r.register(&fileReader{
+		name:       "pdf",
+		extensions: []string{".pdf"},
+		sniff:      func(head []byte) bool { return bytes.HasPrefix(head, []byte("%PDF-")) },
+		format:     chunker.FormatText,
+		read:       a.readPDF,
+	})
+	r.register(&fileReader{
+		name:       "docx",
+		extensions: []string{".docx"},
+		// Любой OOXML — zip, поэтому проверяется ещё и имя части в первой записи архива
+		sniff: func(head []byte) bool {
+			return bytes.HasPrefix(head, []byte("PK\x03\x04")) &&
+				(bytes.Contains(head, []byte("word/")) || bytes.Contains(head, []byte("[Content_Types].xml")))
+		},
+		format:    chunker.FormatMarkdown,
+		converted: true,
+		read:      a.readDOCX,
+	})
+	r.register(&fileReader{
+		name:       "html",
+		extensions: []string{".html", ".htm"},
+		sniff: func(head []byte) bool {
+			head = bytes.ToLower(bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF"))))
+			return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+		},
+		format:    chunker.FormatMarkdown,
+		converted: true,
+		formatOf:  htmlFormat,
+		read:      a.readHTML,
+	})
+	return r, nil
+}
+
+// readText читает .txt и .md с определением кодировки
+func (a *App) readText(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text, encoding, err := decodeText(data, a.cfg.TextEncoding)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if encoding != encodingUTF8 {
+		a.logger.Infof("🔤 %s: converted from %s", filepath.Base(path), encoding)
+	}
+	return text, nil
+}
+
+// commandReader конвертирует файл внешней программой (pandoc, soffice), которая печатает текст в stdout
+type commandReader struct {
+	args       []string
+	extensions []string
+	format     string
+}
+
+func newCommandReader(command string, extensions []string, format string) (*commandReader, error) {
+	switch format {
+	case "":
+		format = chunker.FormatMarkdown
+	case chunker.FormatMarkdown, chunker.FormatText:
+	default:
+		return nil, fmt.Errorf("invalid READER_COMMAND_FORMAT: %s (available: %s, %s)", format, chunker.FormatMarkdown, chunker.FormatText)
+	}
+
+	c := &commandReader{args: strings.Fields(command), format: format}
+	for _, ext := range extensions {
+		if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			c.extensions = append(c.extensions, ext)
+		}
+	}
+	return c, nil
+}
+
+func (c *commandReader) Name() string           { return c.args[0] }
+func (c *commandReader) Extensions() []string   { return c.extensions }
+func (c *commandReader) Sniff(head []byte) bool { return false }
+
+// Read запускает команду; {input} в аргументах заменяется путём к файлу, без {input} путь добавляется в конец
+func (c *commandReader) Read(path string) (Document, error) {
+	args := make([]string, 0, len(c.args)+1)
+	substituted := false
+	for _, arg := range c.args[1:] {
+		if strings.Contains(arg, readerInputPlaceholder) {
+			arg = strings.ReplaceAll(arg, readerInputPlaceholder, path)
+			substituted = true
+		}
+		args = append(args, arg)
+	}
+	if !substituted {
+		args = append(args, path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), readerCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.args[0], args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Document{}, fmt.Errorf("%s failed: %w: %s", c.args[0], err, msg)
+		}
+		return Document{}, fmt.Errorf("%s failed: %w", c.args[0], err)
+	}
+
+	// Кодировка вывода определяется автоматически, а не по TEXT_ENCODING: pandoc печатает UTF-8, даже когда
+	// .txt корпуса в windows-1251, а консольные конвертеры Windows — в кодовой странице системы
+	text, _, err := decodeText(stdout.Bytes(), EncodingAuto)
+	if err != nil {
+		return Document{}, fmt.Errorf("%s output: %w", c.args[0], err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return Document{}, fmt.Errorf("%s produced no text", c.args[0])
+	}
+	return Document{Text: text, Format: c.format, Reader: c.Name(), Converted: true}, nil
+}
+
+// readFile читает документ подходящим reader'ом
+func (a *App) readFile(path string) (Document, error) {
+	reader, err := a.readers.lookup(path)
+	if err != nil {
+		return Document{}, err
+	}
+	a.logger.Debugf("📖 Reading %s with %s reader", filepath.Base(path), reader.Name())
+	return reader.Read(path)
+}
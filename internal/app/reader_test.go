@@ -0,0 +1,98 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"console_rag/internal/chunker"
+	"console_rag/internal/config"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// READER_COMMAND без списка расширений: явно указанный файл конвертируется, вывод в windows-1251
+// переводится в UTF-8, а при обходе директории конвертер не применяется к посторонним файлам
+func TestCommandReader(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not found")
+	}
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	legacy, err := charmap.Windows1251.NewEncoder().Bytes([]byte(encodingSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rtf := write("act.rtf", legacy)
+	write("rules.md", []byte("## Раздел А\n\nТекст."))
+	write("logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00"))
+	write(".gitignore", []byte("*.gob\n"))
+
+	// newCommandApp — приложение с конвертером cat; параметры chunking newTestApp заполняет значениями по умолчанию,
+	// поэтому READER_COMMAND задаётся после и reader'ы собираются заново
+	newCommandApp := func(extensions ...string) *App {
+		t.Helper()
+		a := newTestApp(t, &config.Config{ReferenceDoc: dir})
+		a.cfg.ReaderCommand = "cat {input}"
+		a.cfg.ReaderCommandExtensions = extensions
+		a.cfg.ReaderCommandFormat = chunker.FormatText
+		if a.readers, err = a.newReaders(); err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	a := newCommandApp()
+
+	t.Run("converts a file named explicitly", func(t *testing.T) {
+		doc, err := a.readFile(rtf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.Text != encodingSample {
+			t.Errorf("text %q, want %q", doc.Text, encodingSample)
+		}
+		if doc.Reader != "cat" || doc.Format != chunker.FormatText || !doc.Converted {
+			t.Errorf("document %+v", doc)
+		}
+		files, err := a.referenceFiles(rtf)
+		if err != nil || len(files) != 1 {
+			t.Errorf("reference file: %q, %v", files, err)
+		}
+	})
+
+	t.Run("directory scan skips unrecognized files", func(t *testing.T) {
+		files, err := a.referenceFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{filepath.Join(dir, "rules.md")}; !slices.Equal(files, want) {
+			t.Errorf("files %q, want %q", files, want)
+		}
+	})
+
+	t.Run("declared extensions are scanned", func(t *testing.T) {
+		a := newCommandApp("rtf")
+		files, err := a.referenceFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{rtf, filepath.Join(dir, "rules.md")}; !slices.Equal(files, want) {
+			t.Errorf("files %q, want %q", files, want)
+		}
+	})
+
+	t.Run("empty output", func(t *testing.T) {
+		if _, err := a.readFile(write("empty.rtf", nil)); err == nil {
+			t.Error("expected an error for a file converted to no text")
+		}
+	})
+}
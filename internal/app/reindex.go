@@ -0,0 +1,226 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/philippgille/chromem-go"
+)
+
+// syncCorpus приводит индекс в соответствие с файлами корпуса. Файл переиндексируется, если изменились
+// его время изменения или размер и при этом hash содержимого; файлы, которых больше нет в корпусе,
+// удаляются из индекса. БД и метаданные сохраняются, только если что-то изменилось
+func (a *App) syncCorpus(ctx context.Context, files []string) error {
+	coll := a.db.GetCollection("docs", a.embeddingFunc)
+	if coll == nil {
+		var err error
+		coll, err = a.db.CreateCollection("docs", nil, a.embeddingFunc)
+		if err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+	}
+
+	changed := false
+	reindexed, removed := 0, 0
+	current := make(map[string]bool, len(files))
+	for _, path := range files {
+		name := a.sourceName(path)
+		current[name] = true
+
+		known, ok := a.metadata.Files[name]
+		if ok {
+			stat, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if stat.ModTime().Equal(known.LastModified) && stat.Size() == known.Size {
+				continue
+			}
+			// Время изменения сдвинулось, а содержимое то же (копирование, git checkout) — переиндексация не нужна
+			hash, err := fileHash(path)
+			if err != nil {
+				return err
+			}
+			if hash == known.ContentHash {
+				known.LastModified, known.Size = stat.ModTime(), stat.Size()
+				a.metadata.Files[name] = known
+				changed = true
+				continue
+			}
+			a.logger.Infof("✏️  %s changed, re-indexing...", name)
+		} else {
+			a.logger.Infof("➕ %s is new, indexing...", name)
+		}
+
+		if err := a.reindexFile(ctx, coll, path, known); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		reindexed++
+		changed = true
+	}
+
+	for name, known := range a.metadata.Files {
+		if current[name] {
+			continue
+		}
+		a.logger.Infof("➖ %s is no longer in the corpus, removing...", name)
+		if err := a.removeIndexed(ctx, coll, name, known); err != nil {
+			return err
+		}
+		delete(a.metadata.Files, name)
+		removed++
+		changed = true
+	}
+
+	if !changed {
+		a.logger.Infof("✅ Index is up to date")
+		return nil
+	}
+
+	if err := a.saveMetadata(); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	a.logger.Infof("💾 Saving vector database...")
+	if err := a.saveDB(); err != nil {
+		return fmt.Errorf("failed to save database: %w", err)
+	}
+
+	a.logger.Infof("✅ Index updated: %d document(s) indexed, %d removed, %d chunks in total", reindexed, removed, coll.Count())
+	return nil
+}
+
+// reindexFile заново разбивает файл и обновляет его чанки в коллекции. Embedding вычисляется только
+// для чанков с новым content hash: для остальных берётся из прежней версии файла. known — прежняя
+// запись Metadata.Files (пустая для нового файла)
+func (a *App) reindexFile(ctx context.Context, coll *chromem.Collection, path string, known FileInfo) error {
+	chunks, info, err := a.indexFile(path)
+	if err != nil {
+		return err
+	}
+
+	// В режиме parent–child векторизуются дочерние чанки, а родительские хранятся без embedding
+	indexed := chunks
+	if a.cfg.ParentChild {
+		indexed = a.chunkerFactory.Children(chunks)
+		a.logger.Infof("👶 Split into %d child chunks for retrieval", len(indexed))
+	}
+
+	docs := chunkDocuments(indexed)
+	reused := a.reuseEmbeddings(ctx, coll, known.Chunks, docs)
+
+	// Чанки прежней версии, которых больше нет (ID новых чанков перезапишутся при добавлении)
+	if err := a.removeIndexed(ctx, coll, info.Path, known, docs...); err != nil {
+		return err
+	}
+
+	if a.cfg.ParentChild {
+		if err := a.storeParents(ctx, chunks); err != nil {
+			return err
+		}
+		for _, parent := range chunks {
+			info.Parents = append(info.Parents, parent.ID)
+		}
+	}
+
+	a.logger.Infof("🔄 Adding chunks to vector database (%d new embeddings, %d reused)...", len(docs)-reused, reused)
+
+	// Батчевое добавление с контролем concurrency (вместо последовательного с sleep)
+	if err := coll.AddDocuments(ctx, docs, a.cfg.MaxConcurrency); err != nil {
+		return fmt.Errorf("failed to add chunks to database: %w", err)
+	}
+
+	for _, doc := range docs {
+		info.Chunks = append(info.Chunks, doc.ID)
+	}
+	a.metadata.Files[info.Path] = info
+
+	a.logger.Infof("✅ Successfully added %d chunks to vector database", len(docs))
+	return nil
+}
+
+// reuseEmbeddings переносит embedding'и прежних чанков файла в новые документы с тем же content hash.
+// Возвращает число документов, которым embedding вычислять не нужно
+func (a *App) reuseEmbeddings(ctx context.Context, coll *chromem.Collection, oldIDs []string, docs []chromem.Document) int {
+	embeddings := make(map[string][]float32, len(oldIDs))
+	for _, id := range oldIDs {
+		old, err := coll.GetByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		if hash := old.Metadata["content_hash"]; hash != "" {
+			embeddings[hash] = old.Embedding
+		}
+	}
+
+	reused := 0
+	for i := range docs {
+		if embedding, ok := embeddings[docs[i].Metadata["content_hash"]]; ok {
+			docs[i].Embedding = embedding
+			reused++
+		}
+	}
+	return reused
+}
+
+// removeIndexed удаляет из коллекций чанки файла name, кроме документов keep. Если ID чанков
+// не записаны (метаданные старой версии или утеряны), удаляется всё с Source == name
+func (a *App) removeIndexed(ctx context.Context, coll *chromem.Collection, name string, known FileInfo, keep ...chromem.Document) error {
+	parents := a.db.GetCollection(parentsCollection, nil)
+
+	if known.Chunks == nil {
+		where := map[string]string{"source": name}
+		if err := coll.Delete(ctx, where, nil); err != nil {
+			return fmt.Errorf("failed to delete chunks of %s: %w", name, err)
+		}
+		if parents != nil {
+			if err := parents.Delete(ctx, where, nil); err != nil {
+				return fmt.Errorf("failed to delete parent chunks of %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, doc := range keep {
+		kept[doc.ID] = true
+	}
+	var stale []string
+	for _, id := range known.Chunks {
+		if !kept[id] {
+			stale = append(stale, id)
+		}
+	}
+	if len(stale) > 0 {
+		if err := coll.Delete(ctx, nil, nil, stale...); err != nil {
+			return fmt.Errorf("failed to delete chunks of %s: %w", name, err)
+		}
+		a.logger.Infof("🗑️  Removed %d outdated chunks of %s", len(stale), name)
+	}
+
+	// Родительские чанки не векторизуются, поэтому удаляются все и сохраняются заново
+	if parents != nil && len(known.Parents) > 0 {
+		if err := parents.Delete(ctx, nil, nil, known.Parents...); err != nil {
+			return fmt.Errorf("failed to delete parent chunks of %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fileHash — sha256 содержимого файла
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"console_rag/internal/chunker"
+	"console_rag/internal/config"
+	"console_rag/internal/normalize"
+
+	"github.com/philippgille/chromem-go"
+)
+
+// newIndexTestApp собирает приложение для индексации корпуса dir в БД в памяти. Embedding — hash текста;
+// счётчик показывает, сколько векторов вычислено
+func newIndexTestApp(t *testing.T, dir string) (*App, *atomic.Int64) {
+	t.Helper()
+	a := newTestApp(t, &config.Config{ReferenceDoc: dir, MaxConcurrency: 2})
+	a.cfg.ChunkMethod = "markdown"
+
+	var embedded atomic.Int64
+	a.embeddingFunc = func(ctx context.Context, text string) ([]float32, error) {
+		embedded.Add(1)
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		sum := h.Sum64()
+		return []float32{float32(sum&0xFF) + 1, float32(sum>>8&0xFF) + 1, float32(sum>>16&0xFF) + 1}, nil
+	}
+
+	var err error
+	if a.referenceNormalize, err = normalize.New(a.cfg.NormalizeReference, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.chunkerConfig, err = newChunkerConfig(a.cfg, nil, a.referenceNormalize); err != nil {
+		t.Fatal(err)
+	}
+	a.chunkerFactory = chunker.NewFactory(a.chunkerConfig)
+
+	data := t.TempDir()
+	a.fileDB = filepath.Join(data, "index.gob")
+	a.fileMetadata = filepath.Join(data, "index_metadata.json")
+	a.db = chromem.NewDB()
+	a.metadata = &Metadata{Files: make(map[string]FileInfo)}
+	return a, &embedded
+}
+
+// testDocument — markdown, каждый раздел которого становится отдельным чанком (разбиение по H2 — от трёх разделов)
+func testDocument(sections ...string) string {
+	var b strings.Builder
+	for i, text := range sections {
+		b.WriteString("## Раздел " + string(rune('А'+i)) + "\n\n" + text + "\n\n")
+	}
+	return b.String()
+}
+
+func TestSyncCorpus(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vacation := "Работнику предоставляется ежегодный оплачиваемый отпуск продолжительностью 28 календарных дней."
+	salary := "Заработная плата выплачивается не реже чем каждые полмесяца в день, установленный правилами."
+	discipline := "За совершение дисциплинарного проступка работодатель имеет право применить замечание или выговор."
+	hours := "Нормальная продолжительность рабочего времени не может превышать сорока часов в неделю."
+	write("leave.md", testDocument(vacation, salary, discipline, hours))
+	write("pay.md", testDocument(salary, discipline, vacation, hours))
+	write("rules.md", testDocument(discipline, vacation, salary, hours))
+
+	a, embedded := newIndexTestApp(t, dir)
+	ctx := context.Background()
+	resync := func() {
+		t.Helper()
+		files, err := a.referenceFiles(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.syncCorpus(ctx, files); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resync()
+	coll := a.db.GetCollection("docs", a.embeddingFunc)
+	if len(a.metadata.Files) != 3 || coll.Count() != 12 {
+		t.Fatalf("indexed %d files, %d chunks, want 3 and 12", len(a.metadata.Files), coll.Count())
+	}
+	if _, err := os.Stat(a.fileDB); err != nil {
+		t.Errorf("database not saved: %v", err)
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		// Время изменения сдвинулось, содержимое то же: нужна только новая запись времени в метаданных
+		later := time.Now().Add(time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, "leave.md"), later, later); err != nil {
+			t.Fatal(err)
+		}
+		before := a.metadata.Files["leave.md"].Chunks
+		embedded.Store(0)
+		resync()
+		if n := embedded.Load(); n != 0 {
+			t.Errorf("computed %d embeddings for unchanged files", n)
+		}
+		if coll.Count() != 12 {
+			t.Errorf("%d chunks, want 12", coll.Count())
+		}
+		info := a.metadata.Files["leave.md"]
+		if !info.LastModified.Equal(later) || strings.Join(info.Chunks, ",") != strings.Join(before, ",") {
+			t.Errorf("metadata of leave.md: %+v", info)
+		}
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		edited := "Заработная плата выплачивается не реже чем каждые две недели путём перевода на карту работника."
+		write("pay.md", testDocument(edited, discipline, vacation))
+		old := a.metadata.Files["pay.md"].Chunks
+		embedded.Store(0)
+		resync()
+
+		// Разделы с прежним текстом берут embedding из индекса, вычисляется только изменённый
+		if n := embedded.Load(); n != 1 {
+			t.Errorf("computed %d embeddings, want 1 for the edited section", n)
+		}
+		chunks := a.metadata.Files["pay.md"].Chunks
+		if len(chunks) != 3 || coll.Count() != 11 {
+			t.Fatalf("pay.md has %d chunks, %d in total; want 3 and 11", len(chunks), coll.Count())
+		}
+		for _, id := range old[len(chunks):] {
+			if _, err := coll.GetByID(ctx, id); err == nil {
+				t.Errorf("stale chunk %s is still indexed", id)
+			}
+		}
+		doc, err := coll.GetByID(ctx, chunks[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(doc.Content, "две недели") {
+			t.Errorf("chunk %s has old text %q", chunks[0], doc.Content)
+		}
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		old := a.metadata.Files["rules.md"].Chunks
+		if err := os.Remove(filepath.Join(dir, "rules.md")); err != nil {
+			t.Fatal(err)
+		}
+		embedded.Store(0)
+		resync()
+
+		if _, ok := a.metadata.Files["rules.md"]; ok {
+			t.Error("rules.md is still in metadata")
+		}
+		for _, id := range old {
+			if _, err := coll.GetByID(ctx, id); err == nil {
+				t.Errorf("chunk %s of the removed file is still indexed", id)
+			}
+		}
+		if coll.Count() != 7 || embedded.Load() != 0 {
+			t.Errorf("%d chunks, %d embeddings; want 7 and 0", coll.Count(), embedded.Load())
+		}
+	})
+}
@@ -19,9 +19,8 @@ func (a *App) Run(ctx context.Context) error {
 			return fmt.Errorf("check document not found: %s", a.cfg.CheckDoc)
 		}
 
-		ext := filepath.Ext(a.cfg.CheckDoc)
-		if ext != ".md" && ext != ".txt" && ext != ".pdf" {
-			return fmt.Errorf("unsupported format: %s", ext)
+		if _, err := a.readers.lookup(a.cfg.CheckDoc); err != nil {
+			return err
 		}
 
 		if a.outputPath == "" {
@@ -79,9 +78,8 @@ func (a *App) handleFile(ctx context.Context, path string) {
 	a.logger.Infof("Received input: %s", path)
 
 	if info, err := os.Stat(path); err == nil && !info.IsDir() {
-		ext := filepath.Ext(path)
-		if ext != ".md" && ext != ".txt" && ext != ".pdf" {
-			a.logger.Errorf("❌ Unsupported format: %s", ext)
+		if _, err := a.readers.lookup(path); err != nil {
+			a.logger.Errorf("❌ %v", err)
 			return
 		}
 
@@ -101,6 +99,8 @@ func (a *App) handleFile(ctx context.Context, path string) {
 	}
 
 	// Это просто текст - обрабатываем как раньше
+	path = a.checkNormalize.Apply(path, "query")
+	a.checkNormalize.LogSummary()
 	results, err := a.searchRelevantChunks(ctx, path)
 	if err != nil {
 		a.logger.Errorf("❌ Search error: %v", err)
@@ -109,7 +109,7 @@ func (a *App) handleFile(ctx context.Context, path string) {
 
 	a.logger.Infof("🔍 Found %d relevant sections:", len(results))
 	for i, r := range results {
-		a.logger.Infof("   %d. %s (similarity: %.2f)", i+1, r.Section, r.Similarity)
+		a.logger.Infof("   %d. [%s] %s (similarity: %.2f) %s", i+1, r.Source, r.Section, r.Similarity, r.Position)
 	}
 
 	a.logger.Infof("\n🤖 Analyzing with LLM...")
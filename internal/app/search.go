@@ -3,14 +3,131 @@ package app
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"console_rag/internal/chunker"
 )
 
+// parentsCollection — коллекция родительских чанков в режиме PARENT_CHILD
+const parentsCollection = "parents"
+
+// childOverfetch — во сколько раз больше дочерних чанков запрашивается при поиске:
+// несколько детей одного родителя схлопываются в один результат
+const childOverfetch = 4
+
 // SearchResult - результат векторного поиска
 type SearchResult struct {
 	Content    string
 	Section    string
 	Source     string
 	Similarity float32
+	Position   Position // Положение в эталонном документе
+	AmendedBy  string   // Изменяющие акты из пометок "(В редакции ...)" — для отображения, в промпт не идут
+	Amendments []string // Исходный текст этих пометок, по одной на элемент
+	Repealed   bool
+	Document   map[string]string // Поля front matter эталонного документа (редакция, дата, юрисдикция)
+	Duplicates []Duplicate       // Места эталона с почти тем же текстом, слитые с этим чанком при индексации
+}
+
+// Duplicate — чанк эталона, поглощённый при дедупликации (chunker.MergedFrom)
+type Duplicate struct {
+	Section  string
+	Source   string
+	Article  string
+	Position Position
+}
+
+// amendmentNotes разбирает пометки об изменениях, записанные chunker'ом по одной на строку
+func amendmentNotes(notes string) []string {
+	var result []string
+	for _, note := range strings.Split(notes, "\n") {
+		if note = strings.TrimSpace(note); note != "" {
+			result = append(result, note)
+		}
+	}
+	return result
+}
+
+// duplicatesFromMetadata читает поглощённые дубликаты из метаданных чанка
+func duplicatesFromMetadata(metadata map[string]string) []Duplicate {
+	entries := chunker.MergedFrom(metadata)
+	if len(entries) == 0 {
+		return nil
+	}
+	duplicates := make([]Duplicate, 0, len(entries))
+	for _, entry := range entries {
+		duplicates = append(duplicates, Duplicate{
+			Section:  entry["section"],
+			Source:   entry["source"],
+			Article:  entry["article"],
+			Position: positionFromMetadata(entry),
+		})
+	}
+	return duplicates
+}
+
+// convertedByKey — метаданные чанка: имя reader'а, если текст документа получен преобразованием (Document.Converted)
+const convertedByKey = "converted_by"
+
+// Position — положение чанка в исходном файле. Для DOCX и HTML смещения и строки относятся к Markdown,
+// в который reader преобразовал документ, а не к байтам исходного файла: это видно по ConvertedBy.
+// Нулевые значения — неизвестно
+type Position struct {
+	// Смещения — байты текста после декодирования в UTF-8: для .txt в windows-1251 или UTF-16 это не байты файла
+	StartOffset int
+	EndOffset   int
+	StartLine   int
+	EndLine     int
+	StartPage   int
+	EndPage     int
+	ConvertedBy string // reader, преобразовавший документ ("docx", "html"); пусто — положение в самом файле
+}
+
+// positionFromMetadata читает положение, записанное chunker.AnnotatePositions
+func positionFromMetadata(metadata map[string]string) Position {
+	atoi := func(key string) int {
+		n, _ := strconv.Atoi(metadata[key])
+		return n
+	}
+	return Position{
+		StartOffset: atoi("start_offset"),
+		EndOffset:   atoi("end_offset"),
+		StartLine:   atoi("start_line"),
+		EndLine:     atoi("end_line"),
+		StartPage:   atoi("start_page"),
+		EndPage:     atoi("end_page"),
+		ConvertedBy: metadata[convertedByKey],
+	}
+}
+
+// String описывает положение для отчёта: "стр. 2–3, строки 40–57",
+// у преобразованного документа — "строки 40–57 (по тексту после конвертации docx)"
+func (p Position) String() string {
+	var loc string
+	if p.StartPage > 0 {
+		loc = "стр. " + formatRange(p.StartPage, p.EndPage) + ", "
+	}
+	if p.StartLine == 0 {
+		return ""
+	}
+	if p.StartLine == p.EndLine {
+		loc += "строка " + strconv.Itoa(p.StartLine)
+	} else {
+		loc += "строки " + formatRange(p.StartLine, p.EndLine)
+	}
+	if p.ConvertedBy != "" {
+		loc += " (по тексту после конвертации " + p.ConvertedBy + ")"
+	}
+	return loc
+}
+
+func formatRange(from, to int) string {
+	if to <= from {
+		return strconv.Itoa(from)
+	}
+	return fmt.Sprintf("%d–%d", from, to)
 }
 
 // searchRelevantChunks ищет релевантные чанки в reference-doc
@@ -24,26 +141,109 @@ func (a *App) searchRelevantChunks(
 		return nil, fmt.Errorf("collection 'docs' not found")
 	}
 
+	// Если индекс построен в режиме parent–child, ищем по детям с запасом
+	parents := a.db.GetCollection(parentsCollection, nil)
+	nResults := a.cfg.TopK
+	if parents != nil {
+		nResults *= childOverfetch
+	}
+	nResults = min(nResults, coll.Count())
+	if nResults == 0 {
+		return nil, nil
+	}
+
+	// Утратившие силу чанки отсекаются в самом поиске: у каждого чанка есть поле repealed
+	var where map[string]string
+	if a.cfg.ExcludeRepealed {
+		where = map[string]string{"repealed": "false"}
+	}
+
 	// Выполняем поиск
-	results, err := coll.Query(ctx, queryText, a.cfg.TopK, nil, nil)
+	results, err := coll.Query(ctx, queryText, nResults, where, nil)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 
 	// Фильтруем по similarity и преобразуем
 	var searchResults []SearchResult
+	seenParents := make(map[string]bool)
 	for _, r := range results {
+		if len(searchResults) >= a.cfg.TopK {
+			break
+		}
 		if r.Similarity < a.cfg.MinSimilarity {
 			continue
 		}
+		repealed := r.Metadata["repealed"] == "true"
+
+		content := r.Content
+		if parentID := r.Metadata["parent_id"]; parents != nil && parentID != "" {
+			// Результаты отсортированы по убыванию similarity: первый ребёнок родителя — лучший
+			if seenParents[parentID] {
+				continue
+			}
+			seenParents[parentID] = true
+
+			parent, err := parents.GetByID(ctx, parentID)
+			if err != nil {
+				a.logger.Errorf("⚠️  Parent chunk %s not found: %v", parentID, err)
+			} else {
+				content = parent.Content
+			}
+		}
 
 		searchResults = append(searchResults, SearchResult{
-			Content:    r.Content,
+			Content:    content,
 			Section:    r.Metadata["section"],
 			Source:     r.Metadata["source"],
 			Similarity: r.Similarity,
+			Position:   positionFromMetadata(r.Metadata),
+			AmendedBy:  r.Metadata["amended_by"],
+			Amendments: amendmentNotes(r.Metadata["amendment_notes"]),
+			Repealed:   repealed,
+			Document:   frontMatterFromMetadata(r.Metadata),
+			Duplicates: duplicatesFromMetadata(r.Metadata),
 		})
 	}
 
 	return searchResults, nil
 }
+
+// frontMatterFromMetadata собирает поля front matter, записанные в метаданные чанка с префиксом chunker.FrontMatterPrefix
+func frontMatterFromMetadata(metadata map[string]string) map[string]string {
+	var fields map[string]string
+	for key, value := range metadata {
+		name, ok := strings.CutPrefix(key, chunker.FrontMatterPrefix)
+		if !ok {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// documentLabel описывает документ для отчёта: "title: ТК РФ; edition: 2024-09-01"
+func documentLabel(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + fields[key]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// documentName — название документа, из которого найден фрагмент: title из front matter или имя файла
+func documentName(r SearchResult) string {
+	if title := r.Document["title"]; title != "" {
+		return title
+	}
+	return r.Source
+}
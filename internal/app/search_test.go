@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// В режиме parent–child поиск идёт по дочерним чанкам, а в результат попадает родитель — один раз,
+// сколько бы его детей ни нашлось
+func TestSearchResolvesParents(t *testing.T) {
+	dir := t.TempDir()
+	sentence := func(topic string, n int) string {
+		return strings.Repeat(topic+" регулируется локальным нормативным актом организации. ", n)
+	}
+	content := testDocument(sentence("Отпуск", 3), sentence("Оплата труда", 4), sentence("Режим рабочего времени", 2))
+	if err := os.WriteFile(filepath.Join(dir, "rules.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := newIndexTestApp(t, dir)
+	a.cfg.ParentChild = true
+	a.cfg.TopK = 5
+	ctx := context.Background()
+	if err := a.syncCorpus(ctx, []string{filepath.Join(dir, "rules.md")}); err != nil {
+		t.Fatal(err)
+	}
+	info := a.metadata.Files["rules.md"]
+	if len(info.Parents) != 3 || len(info.Chunks) != 9 {
+		t.Fatalf("indexed %d parents and %d children, want 3 and 9", len(info.Parents), len(info.Chunks))
+	}
+
+	parents := a.db.GetCollection(parentsCollection, nil)
+	want := make(map[string]bool)
+	for _, id := range info.Parents {
+		parent, err := parents.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[parent.Content] = true
+	}
+
+	// TopK больше числа родителей: все девять детей находятся, но родителей только три
+	results, err := a.searchRelevantChunks(ctx, "Оплата труда")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want one per parent", len(results))
+	}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if !want[r.Content] {
+			t.Errorf("result is not a parent chunk: %q", r.Content)
+		}
+		if seen[r.Content] {
+			t.Errorf("parent returned twice: %q", r.Content)
+		}
+		seen[r.Content] = true
+	}
+}
@@ -0,0 +1,128 @@
+package chunker
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"console_rag/internal/normalize"
+)
+
+// repealedMaxLetters — если после удаления пометок и заголовка в чанке осталось меньше букв,
+// а пометка говорит об утрате силы, чанк считается утратившим силу
+const repealedMaxLetters = 30
+
+var (
+	// Пометка об изменении — общий шаблон с шагом нормализации edition-notes
+	reAmendmentNote = normalize.EditionNote
+	reAmendingLaw   = regexp.MustCompile(`(?i)от[\s\x{00A0}]+(\d{1,2})\.(\d{2})\.(\d{4})(?:[\s\x{00A0}]*г\.)?[\s\x{00A0}]*№[\s\x{00A0}]*([\dА-ЯЁа-яё]+(?:-[\dА-ЯЁа-яё]+)*)`)
+	reRepealedNote  = regexp.MustCompile(`(?i)утратил[аио]?[\s\x{00A0}]+силу|утратившим[\s\x{00A0}]+силу|исключен`)
+	reAddedNote     = regexp.MustCompile(`(?i)дополнен`)
+	reRepealedStub  = regexp.MustCompile(`(?im)^(?:статья\s+\d+(?:[.-]\d+)*\..*|.*\s>\s.*|часть|пункт|подпункт|абзац)\.?\s*$`)
+	reNoteGaps      = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+)
+
+// markInForce записывает repealed="false" чанкам без пометок об изменениях: поиск отбирает
+// действующие чанки фильтром по этому полю, поэтому оно должно быть у каждого чанка
+func markInForce(chunks []Chunk) {
+	for i := range chunks {
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = make(map[string]string)
+		}
+		if _, ok := chunks[i].Metadata["repealed"]; !ok {
+			chunks[i].Metadata["repealed"] = "false"
+		}
+	}
+}
+
+// ExtractAmendments убирает из текста чанков пометки об изменениях ("В редакции Федерального закона ...")
+// и раскладывает их в метаданные: amendment_notes (исходный текст для отображения), amended_by (законы),
+// last_amended (дата последнего изменения, YYYY-MM-DD), amendment_status (amended, added, repealed)
+// и repealed ("true", если от статьи осталась только пометка об утрате силы).
+// Пометки не попадают в embedding и не занимают место в промпте. Опустевшие чанки отбрасываются
+func ExtractAmendments(chunks []Chunk) []Chunk {
+	result := chunks[:0]
+	for _, chunk := range chunks {
+		if chunk.Metadata == nil {
+			chunk.Metadata = make(map[string]string)
+		}
+		chunk.Metadata["repealed"] = "false"
+
+		notes := reAmendmentNote.FindAllString(chunk.Text, -1)
+		if len(notes) == 0 {
+			result = append(result, chunk)
+			continue
+		}
+
+		text := reAmendmentNote.ReplaceAllString(chunk.Text, "")
+		text = strings.TrimSpace(reNoteGaps.ReplaceAllString(text, "\n\n"))
+
+		var laws []string
+		var statuses []string
+		seenLaw := make(map[string]bool)
+		seenStatus := make(map[string]bool)
+		lastAmended := ""
+		repealNote := false
+		for i, note := range notes {
+			notes[i] = strings.TrimSpace(note)
+
+			status := "amended"
+			switch {
+			case reRepealedNote.MatchString(note):
+				status = "repealed"
+				repealNote = true
+			case reAddedNote.MatchString(note):
+				status = "added"
+			}
+			if !seenStatus[status] {
+				seenStatus[status] = true
+				statuses = append(statuses, status)
+			}
+
+			for _, m := range reAmendingLaw.FindAllStringSubmatch(note, -1) {
+				day := m[1]
+				if len(day) == 1 {
+					day = "0" + day
+				}
+				date := m[3] + "-" + m[2] + "-" + day
+				law := m[4] + " от " + day + "." + m[2] + "." + m[3]
+				if !seenLaw[law] {
+					seenLaw[law] = true
+					laws = append(laws, law)
+				}
+				if date > lastAmended {
+					lastAmended = date
+				}
+			}
+		}
+
+		chunk.Metadata["amendment_notes"] = strings.Join(notes, "\n")
+		chunk.Metadata["amendment_status"] = strings.Join(statuses, ",")
+		if len(laws) > 0 {
+			chunk.Metadata["amended_by"] = strings.Join(laws, "; ")
+		}
+		if lastAmended != "" {
+			chunk.Metadata["last_amended"] = lastAmended
+		}
+		if repealNote && countLetters(reRepealedStub.ReplaceAllString(text, "")) < repealedMaxLetters {
+			chunk.Metadata["repealed"] = "true"
+		}
+
+		if text == "" {
+			continue
+		}
+		chunk.Text = text
+		result = append(result, chunk)
+	}
+	return result
+}
+
+func countLetters(text string) int {
+	n := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package chunker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// childMinRunes — дочерние чанки короче этого (заголовок статьи, "1)") присоединяются к соседнему
+const childMinRunes = 40
+
+// Граница абзаца или перенос строки перед маркером пункта
+var reChildBreak = regexp.MustCompile(`\n[ \t]*\n\s*|\n[ \t]*(?:\d+(?:\.\d+)*[.)]|[а-яёa-z]\))[\s\x{00A0}]`)
+
+// Children разбивает родительские чанки на мелкие дочерние для точного поиска (small-to-big):
+// нумерованный пункт целиком, если он помещается в MaxChunkSize, иначе — отдельные предложения.
+// Дочерний чанк ссылается на родителя через Metadata["parent_id"]
+func (f *Factory) Children(parents []Chunk) []Chunk {
+	var children []Chunk
+	for _, parent := range parents {
+		units := mergeTinyUnits(f.childUnits(parent.Text))
+		for i, unit := range units {
+			metadata := make(map[string]string, len(parent.Metadata)+2)
+			for k, v := range parent.Metadata {
+				metadata[k] = v
+			}
+			metadata["parent_id"] = parent.ID
+			metadata["child_num"] = fmt.Sprintf("%d", i+1)
+
+			contentHash := ContentHash(unit)
+			metadata["content_hash"] = contentHash
+
+			children = append(children, Chunk{
+				ID:          fmt.Sprintf("%s-%d", parent.ID, i+1),
+				ContentHash: contentHash,
+				Text:        unit,
+				Source:      parent.Source,
+				Section:     parent.Section,
+				Metadata:    metadata,
+			})
+		}
+	}
+	return children
+}
+
+// childUnits делит текст на пункты и абзацы, а слишком длинные и не нумерованные — на предложения
+func (f *Factory) childUnits(text string) []string {
+	var blocks []string
+	last := 0
+	for _, loc := range reChildBreak.FindAllStringIndex(text, -1) {
+		// Перенос перед маркером пункта: сам маркер остаётся в следующем блоке
+		end := loc[0]
+		next := loc[1]
+		if strings.TrimSpace(text[loc[0]:loc[1]]) != "" {
+			next = loc[0] + 1
+		}
+		blocks = append(blocks, text[last:end])
+		last = next
+	}
+	blocks = append(blocks, text[last:])
+
+	var units []string
+	for _, block := range blocks {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		if reListMarker.MatchString(block) && f.config.Size(block) <= f.config.MaxChunkSize {
+			units = append(units, strings.Join(strings.Fields(block), " "))
+			continue
+		}
+		units = append(units, SplitSentences(block)...)
+	}
+	return units
+}
+
+// mergeTinyUnits присоединяет короткие фрагменты к следующему (последний — к предыдущему)
+func mergeTinyUnits(units []string) []string {
+	var merged []string
+	pending := ""
+	for _, unit := range units {
+		if pending != "" {
+			unit = pending + " " + unit
+			pending = ""
+		}
+		if utf8.RuneCountInString(unit) < childMinRunes {
+			pending = unit
+			continue
+		}
+		merged = append(merged, unit)
+	}
+	if pending != "" {
+		if len(merged) > 0 {
+			merged[len(merged)-1] += " " + pending
+		} else {
+			merged = append(merged, pending)
+		}
+	}
+	return merged
+}
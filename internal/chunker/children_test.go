@@ -0,0 +1,56 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+// Дочерний чанк — пункт статьи целиком или предложение абзаца; заголовок статьи короче childMinRunes
+// присоединяется к первому пункту, метаданные родителя копируются, parent_id ведёт к родителю
+func TestChildren(t *testing.T) {
+	points := Chunk{
+		ID:       "tk-21",
+		Text:     "Статья 21.\n1) заключение, изменение и расторжение трудового договора;\n2) предоставление ему работы, обусловленной трудовым договором;\nа) рабочее место, соответствующее требованиям охраны труда.",
+		Source:   "tk.md",
+		Section:  "Статья 21",
+		Metadata: map[string]string{"article": "21", "heading_path": "Глава 4 > Статья 21"},
+	}
+	paragraph := Chunk{
+		ID:      "tk-22",
+		Text:    "Работодатель обязан соблюдать трудовое законодательство. Работодатель обязан выплачивать заработную плату в полном размере.",
+		Source:  "tk.md",
+		Section: "Статья 22",
+	}
+
+	children := NewFactory(Config{MaxChunkSize: 1000, SizeUnit: SizeRunes}).Children([]Chunk{points, paragraph})
+
+	want := []struct {
+		id, parent, prefix string
+	}{
+		{"tk-21-1", "tk-21", "Статья 21. 1) заключение"},
+		{"tk-21-2", "tk-21", "2) предоставление"},
+		{"tk-21-3", "tk-21", "а) рабочее место"},
+		{"tk-22-1", "tk-22", "Работодатель обязан соблюдать"},
+		{"tk-22-2", "tk-22", "Работодатель обязан выплачивать"},
+	}
+	if len(children) != len(want) {
+		t.Fatalf("got %d children, want %d: %+v", len(children), len(want), children)
+	}
+	for i, w := range want {
+		c := children[i]
+		if c.ID != w.id || c.Metadata["parent_id"] != w.parent || !strings.HasPrefix(c.Text, w.prefix) {
+			t.Errorf("child %d: %s (parent %s) %q, want %s (parent %s) starting with %q",
+				i, c.ID, c.Metadata["parent_id"], c.Text, w.id, w.parent, w.prefix)
+		}
+		if c.ContentHash == "" || c.Metadata["content_hash"] != c.ContentHash {
+			t.Errorf("child %d: content hash %q, metadata %q", i, c.ContentHash, c.Metadata["content_hash"])
+		}
+	}
+	if first := children[0]; first.Source != "tk.md" || first.Section != "Статья 21" || first.Metadata["article"] != "21" ||
+		first.Metadata["heading_path"] != "Глава 4 > Статья 21" {
+		t.Errorf("parent fields not copied: %+v", first)
+	}
+	if _, ok := points.Metadata["parent_id"]; ok {
+		t.Error("parent metadata was modified")
+	}
+}
@@ -0,0 +1,194 @@
+package chunker
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// Параметры MinHash: 64 хэш-функции, LSH на 16 полос по 4 строки.
+// Пара с Jaccard 0.8 попадает в кандидаты с вероятностью > 0.99
+const (
+	shingleWords  = 3
+	minHashSize   = 64
+	minHashBands  = 16
+	minHashRows   = minHashSize / minHashBands
+	minHashSeed   = 0x9E3779B97F4A7C15
+	shingleFNVPad = "\x00"
+)
+
+// MergedFromKey — ключ метаданных с описанием чанков, поглощённых при дедупликации (JSON, см. MergedFrom)
+const MergedFromKey = "merged_from"
+
+// mergedFields — метаданные поглощённого чанка, которые сохраняются в оставленном: номер статьи и положение
+var mergedFields = []string{"article", "start_offset", "end_offset", "start_line", "end_line", "start_page", "end_page", "converted_by"}
+
+// Deduplicate убирает почти одинаковые чанки: word-shingles + MinHash/LSH для поиска кандидатов,
+// затем точное сходство Jaccard по shingles. Остаётся первый чанк группы; ID, секция, статья, источник
+// и положение поглощённых записываются в его Metadata[MergedFromKey], чтобы в отчёте были видны все места,
+// где встречается текст. threshold <= 0 отключает дедупликацию. Возвращает чанки и число удалённых
+func Deduplicate(chunks []Chunk, threshold float64) ([]Chunk, int) {
+	if threshold <= 0 || len(chunks) < 2 {
+		return chunks, 0
+	}
+
+	shingles := make([]map[uint64]struct{}, len(chunks))
+	buckets := make(map[uint64][]int)
+	mergedInto := make([]int, len(chunks))
+
+	for i, chunk := range chunks {
+		mergedInto[i] = -1
+		shingles[i] = shingleSet(chunk.Text)
+		signature := minHash(shingles[i])
+
+		// Ищем уже оставленный чанк, похожий на текущий
+		candidates := make(map[int]bool)
+		keys := make([]uint64, minHashBands)
+		for band := 0; band < minHashBands; band++ {
+			key := uint64(band) * minHashSeed
+			for _, v := range signature[band*minHashRows : (band+1)*minHashRows] {
+				key = mix64(key ^ v)
+			}
+			keys[band] = key
+			for _, j := range buckets[key] {
+				candidates[j] = true
+			}
+		}
+
+		best := -1
+		for j := range candidates {
+			if jaccard(shingles[i], shingles[j]) >= threshold && (best == -1 || j < best) {
+				best = j
+			}
+		}
+		if best >= 0 {
+			mergedInto[i] = best
+			continue
+		}
+
+		// В buckets попадают только оставленные чанки — дубликаты сливаются с первым в группе
+		for _, key := range keys {
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	merged := make(map[int][]map[string]string)
+	for i, target := range mergedInto {
+		if target >= 0 {
+			merged[target] = append(merged[target], mergedEntry(chunks[i]))
+		}
+	}
+
+	result := make([]Chunk, 0, len(chunks)-len(merged))
+	removed := 0
+	for i, chunk := range chunks {
+		if mergedInto[i] >= 0 {
+			removed++
+			continue
+		}
+		if entries := merged[i]; len(entries) > 0 {
+			if chunk.Metadata == nil {
+				chunk.Metadata = make(map[string]string)
+			}
+			data, _ := json.Marshal(entries)
+			chunk.Metadata[MergedFromKey] = string(data)
+		}
+		result = append(result, chunk)
+	}
+
+	return result, removed
+}
+
+// mergedEntry описывает поглощённый чанк: id, section, source и поля mergedFields, если они есть
+func mergedEntry(chunk Chunk) map[string]string {
+	entry := map[string]string{"section": chunk.Section, "source": chunk.Source}
+	if chunk.ID != "" {
+		entry["id"] = chunk.ID
+	}
+	for _, key := range mergedFields {
+		if value := chunk.Metadata[key]; value != "" {
+			entry[key] = value
+		}
+	}
+	return entry
+}
+
+// MergedFrom возвращает описания чанков, поглощённых при дедупликации, из метаданных оставленного чанка
+func MergedFrom(metadata map[string]string) []map[string]string {
+	var entries []map[string]string
+	if data := metadata[MergedFromKey]; data != "" {
+		_ = json.Unmarshal([]byte(data), &entries)
+	}
+	return entries
+}
+
+// shingleSet возвращает хэши последовательностей из shingleWords слов (регистр и пунктуация игнорируются)
+func shingleSet(text string) map[uint64]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	set := make(map[uint64]struct{})
+	if len(words) < shingleWords {
+		set[hashWords(words)] = struct{}{}
+		return set
+	}
+	for i := 0; i+shingleWords <= len(words); i++ {
+		set[hashWords(words[i:i+shingleWords])] = struct{}{}
+	}
+	return set
+}
+
+func hashWords(words []string) uint64 {
+	h := fnv.New64a()
+	for _, w := range words {
+		h.Write([]byte(w))
+		h.Write([]byte(shingleFNVPad))
+	}
+	return h.Sum64()
+}
+
+// minHash вычисляет сигнатуру множества shingles
+func minHash(set map[uint64]struct{}) []uint64 {
+	signature := make([]uint64, minHashSize)
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for s := range set {
+		for i := range signature {
+			if v := mix64(s ^ (uint64(i+1) * minHashSeed)); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// mix64 — финализатор splitmix64: дешёвая хэш-функция для семейства MinHash
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
+
+// jaccard — доля общих shingles
+func jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common := 0
+	for s := range a {
+		if _, ok := b[s]; ok {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
@@ -0,0 +1,60 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	boilerplate := "Работодатель обязан ознакомить работника под подпись с принятыми локальными нормативными актами, " +
+		"непосредственно связанными с его трудовой деятельностью, а также обеспечить их соблюдение всеми работниками организации."
+	article := func(n, days int) string {
+		return fmt.Sprintf("Статья %d. Работнику предоставляется ежегодный дополнительный оплачиваемый отпуск "+
+			"продолжительностью %d календарных дней в порядке и на условиях, определяемых коллективным договором.", n, days)
+	}
+	chunk := func(section, text string) Chunk {
+		return Chunk{ID: "id-" + section, Text: text, Source: "codex.md", Section: section, Metadata: map[string]string{"start_line": section}}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		chunks := []Chunk{chunk("1", boilerplate), chunk("2", boilerplate)}
+		if got, removed := Deduplicate(chunks, 0); len(got) != 2 || removed != 0 {
+			t.Errorf("threshold 0 removed %d chunks", removed)
+		}
+	})
+
+	t.Run("near duplicates merge into the first", func(t *testing.T) {
+		chunks := []Chunk{
+			chunk("1", boilerplate),
+			chunk("2", article(115, 28)),
+			chunk("3", boilerplate+" "),
+			chunk("4", "«"+strings.Replace(boilerplate, "организации", "предприятия", 1)+"»"),
+		}
+		got, removed := Deduplicate(chunks, 0.9)
+		if removed != 2 || len(got) != 2 {
+			t.Fatalf("removed %d, %d left, want 2 and 2", removed, len(got))
+		}
+		if got[0].Section != "1" || got[1].Section != "2" {
+			t.Errorf("kept %s and %s, want 1 and 2", got[0].Section, got[1].Section)
+		}
+		merged := MergedFrom(got[0].Metadata)
+		if len(merged) != 2 || merged[0]["section"] != "3" || merged[1]["start_line"] != "4" {
+			t.Fatalf("merged_from %v", merged)
+		}
+		if merged[0]["id"] != "id-3" || merged[1]["id"] != "id-4" {
+			t.Errorf("merged ids %q and %q, want id-3 and id-4", merged[0]["id"], merged[1]["id"])
+		}
+		if len(MergedFrom(got[1].Metadata)) != 0 {
+			t.Errorf("unique chunk has merged_from %v", got[1].Metadata)
+		}
+	})
+
+	// Соседние статьи кодекса отличаются номером и сроком — это разные нормы, а не дубликаты
+	t.Run("articles differing in numbers survive", func(t *testing.T) {
+		chunks := []Chunk{chunk("1", article(116, 28)), chunk("2", article(117, 14)), chunk("3", article(118, 3))}
+		if got, removed := Deduplicate(chunks, 0.9); removed != 0 || len(got) != 3 {
+			t.Errorf("removed %d of the articles", removed)
+		}
+	})
+}
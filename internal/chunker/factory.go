@@ -2,10 +2,17 @@ package chunker
 
 import (
 	"fmt"
+	"log"
 	"path/filepath"
 	"strings"
 )
 
+// MethodAuto — выбор chunker'а по содержимому и расширению файла
+const MethodAuto = "auto"
+
+// defaultMethod используется, когда ни содержимое, ни расширение не подсказали chunker
+const defaultMethod = "simple"
+
 // Factory создаёт chunker на основе метода и типа файла
 type Factory struct {
 	config Config
@@ -16,36 +23,135 @@ func NewFactory(config Config) *Factory {
 	return &Factory{config: config}
 }
 
-// GetChunker возвращает подходящий chunker для файла
-func (f *Factory) GetChunker(filePath, method string) (Chunker, error) {
-	// Если метод явно указан - используем его
-	switch strings.ToLower(method) {
-	case "markdown", "md":
-		return NewMarkdownChunker(f.config), nil
-	case "simple", "text", "txt":
-		return NewTextChunker(f.config), nil
-	}
-
-	// Иначе определяем по расширению файла
-	ext := strings.ToLower(filepath.Ext(filePath))
-	switch ext {
-	case ".md", ".markdown":
-		return NewMarkdownChunker(f.config), nil
-	case ".txt", ".text":
-		return NewTextChunker(f.config), nil
-	default:
-		return NewTextChunker(f.config), nil
+// GetChunker возвращает подходящий chunker для файла.
+// Явно указанный метод имеет приоритет; при пустом методе или "auto" chunker выбирается
+// по содержимому (Sniff), затем по формату текста от reader'а (format, может быть пустым),
+// затем по расширению файла, иначе — text chunker
+func (f *Factory) GetChunker(filePath, format, method, content string) (Chunker, error) {
+	if method != "" && !strings.EqualFold(method, MethodAuto) {
+		if legal, ok := byFormat(FormatLegal); ok && format == FormatLegal && !strings.EqualFold(method, legal.Name) {
+			log.Printf("ℹ️  Headings were set by the document reader, CHUNK_METHOD=auto or %s would split by articles", legal.Name)
+		}
+		return f.GetChunkerByMethod(method)
+	}
+
+	if r, ok := sniff(content); ok {
+		log.Printf("🔍 Content looks like %s document", r.Name)
+		return r.New(f.config)
+	}
+	if r, ok := byFormat(format); ok {
+		if format == FormatLegal {
+			log.Printf("🔍 Headings were set by the document reader, using %s chunker", r.Name)
+		}
+		return r.New(f.config)
+	}
+	if r, ok := byExtension(filepath.Ext(filePath)); ok {
+		return r.New(f.config)
 	}
+	return f.GetChunkerByMethod(defaultMethod)
 }
 
 // GetChunkerByMethod возвращает chunker по названию метода
 func (f *Factory) GetChunkerByMethod(method string) (Chunker, error) {
-	switch strings.ToLower(method) {
-	case "markdown", "md":
-		return NewMarkdownChunker(f.config), nil
-	case "simple", "text", "txt":
-		return NewTextChunker(f.config), nil
-	default:
-		return nil, fmt.Errorf("unknown chunking method: %s", method)
+	r, ok := lookup(method)
+	if !ok {
+		return nil, fmt.Errorf("unknown chunking method: %s (available: %s)", method, strings.Join(Registered(), ", "))
+	}
+	return r.New(f.config)
+}
+
+// Chunk разбивает документ выбранным chunker'ом. Если chunker недоступен, завершился ошибкой
+// или не создал ни одного чанка, по очереди пробуются chunker'ы из Config.Fallback.
+// format — формат текста от reader'а документа (FormatMarkdown, FormatText или пусто).
+// У markdown-документа front matter отрезается до разбиения любым chunker'ом, а его поля
+// попадают в метаданные всех чанков (FrontMatterPrefix).
+// source — имя документа (Chunk.Source, по нему строятся ID чанков); по его расширению выбирается chunker.
+// Возвращает чанки и имя chunker'а, который их создал
+func (f *Factory) Chunk(content, source, format, method string) ([]Chunk, string, error) {
+	body, frontMatter := content, map[string]string(nil)
+	if format == FormatMarkdown {
+		var err error
+		if body, frontMatter, err = SplitFrontMatter(content); err != nil {
+			log.Printf("⚠️  %v, keeping the block as text", err)
+		}
+		if len(frontMatter) > 0 {
+			log.Printf("🏷️  Front matter: %v", frontMatter)
+		}
+	}
+
+	chain := []func() (Chunker, error){
+		func() (Chunker, error) { return f.GetChunker(source, format, method, body) },
+	}
+	for _, name := range f.config.Fallback {
+		chain = append(chain, func() (Chunker, error) { return f.GetChunkerByMethod(name) })
+	}
+
+	tried := make(map[string]bool)
+	lastErr := fmt.Errorf("no chunker available")
+	for i, next := range chain {
+		chunkr, err := next()
+		if err != nil {
+			log.Printf("⚠️  Chunker unavailable: %v", err)
+			lastErr = err
+			continue
+		}
+		if tried[chunkr.Name()] {
+			continue
+		}
+		tried[chunkr.Name()] = true
+
+		if i == 0 {
+			log.Printf("🔧 Using chunker: %s (size %d %s, overlap %d)",
+				chunkr.Name(), f.config.MaxChunkSize, f.config.SizeUnit, f.config.Overlap)
+		} else {
+			log.Printf("🔄 Falling back to %s chunker...", chunkr.Name())
+		}
+
+		chunks, err := chunkr.Chunk(body, source)
+		if err == nil {
+			// Позиции ищем до нормализации: текст чанка ещё ближе к исходному.
+			// Поиск идёт по полному тексту, чтобы смещения и строки считались от начала файла
+			AnnotatePositions(content, chunks)
+			if f.config.ExtractAmendments {
+				chunks = ExtractAmendments(chunks)
+			} else {
+				markInForce(chunks)
+			}
+			chunks = f.normalize(chunks)
+			var merged int
+			if chunks, merged = MergeSmall(chunks, f.config.MinChunkSize, f.config.MaxChunkSize, f.config.Size); merged > 0 {
+				log.Printf("🧩 Merged %d chunks smaller than %d %s into neighbours", merged, f.config.MinChunkSize, f.config.SizeUnit)
+			}
+		}
+		if err == nil && len(chunks) == 0 {
+			err = fmt.Errorf("no chunks created")
+		}
+		if err != nil {
+			log.Printf("⚠️  %s chunker failed: %v", chunkr.Name(), err)
+			lastErr = err
+			continue
+		}
+		withFrontMatter(chunks, frontMatter)
+		AssignIDs(chunks)
+		return chunks, chunkr.Name(), nil
+	}
+
+	return nil, "", fmt.Errorf("all chunkers failed: %w", lastErr)
+}
+
+// normalize применяет Config.Normalize к тексту чанков и выбрасывает опустевшие
+func (f *Factory) normalize(chunks []Chunk) []Chunk {
+	if f.config.Normalize == nil {
+		return chunks
+	}
+	result := chunks[:0]
+	for _, chunk := range chunks {
+		chunk.Text = strings.TrimSpace(f.config.Normalize(chunk.Text, chunk.Section))
+		if chunk.Text == "" {
+			continue
+		}
+		chunk.ContentHash = ContentHash(chunk.Text)
+		result = append(result, chunk)
 	}
+	return result
 }
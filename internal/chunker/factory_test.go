@@ -0,0 +1,108 @@
+package chunker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// legalAct — текст с пятью статьями: LooksLikeLegalAct распознаёт его при CHUNK_METHOD=auto
+func legalAct() string {
+	var b strings.Builder
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(&b, "Статья %d. Работник обязан соблюдать правила внутреннего трудового распорядка.\n\n", i)
+	}
+	return b.String()
+}
+
+func TestFactoryGetChunker(t *testing.T) {
+	plain := "Работник обязан соблюдать правила внутреннего трудового распорядка."
+	tests := []struct {
+		name    string
+		path    string
+		format  string
+		method  string
+		content string
+		want    string
+	}{
+		{"method", "rules.txt", FormatText, "markdown", plain, "markdown"},
+		{"alias", "rules.md", "", "md", plain, "markdown"},
+		{"alias in upper case", "rules.md", "", " TXT ", plain, "simple"},
+		{"explicit method beats content", "codex.md", "", "markdown", legalAct(), "markdown"},
+		{"auto: content", "codex.txt", FormatText, MethodAuto, legalAct(), "legal"},
+		{"auto: reader format", "rules.docx", FormatMarkdown, MethodAuto, plain, "markdown"},
+		{"auto: legal headings from the reader", "codex.html", FormatLegal, "", plain, "legal"},
+		{"auto: extension", "rules.MD", "", "", plain, "markdown"},
+		{"auto: text extension", "rules.text", "", MethodAuto, plain, "simple"},
+		{"auto: default", "rules", "", MethodAuto, plain, defaultMethod},
+	}
+
+	factory := NewFactory(Config{MaxChunkSize: 1000, SizeUnit: SizeRunes})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunker, err := factory.GetChunker(tt.path, tt.format, tt.method, tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if chunker.Name() != tt.want {
+				t.Errorf("got %s, want %s", chunker.Name(), tt.want)
+			}
+		})
+	}
+
+	if _, err := factory.GetChunker("rules.md", "", "paragraphs", plain); err == nil || !strings.Contains(err.Error(), "available: ") {
+		t.Errorf("unknown method: error %v, want the list of methods", err)
+	}
+}
+
+// Chunk переходит к следующему chunker'у из Config.Fallback, если выбранный недоступен, завершился ошибкой
+// или не создал чанков
+func TestFactoryChunkFallback(t *testing.T) {
+	content := "Работник обязан соблюдать правила внутреннего трудового распорядка.\n\nРаботодатель обязан выплачивать заработную плату."
+	failing := func(ctx context.Context, text string) ([]float32, error) {
+		return nil, errors.New("embedding API unavailable")
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		method  string
+		want    string
+		wantErr string
+	}{
+		{"first chunker succeeds", Config{Fallback: []string{"markdown"}}, "simple", "simple", ""},
+		{"chunker error", Config{Embed: failing, Fallback: []string{"simple"}}, "semantic", "simple", ""},
+		{"chunker unavailable", Config{Fallback: []string{"simple"}}, "semantic", "simple", ""},
+		{"unknown fallback is skipped", Config{Embed: failing, Fallback: []string{"paragraphs", "markdown"}}, "semantic", "markdown", ""},
+		{"all failed", Config{Embed: failing, Fallback: []string{"semantic"}}, "semantic", "", "embedding API unavailable"},
+		{"no fallback", Config{}, "semantic", "", "requires an embedding function"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.MaxChunkSize, tt.config.SizeUnit = 1000, SizeRunes
+			chunks, name, err := NewFactory(tt.config).Chunk(content, "rules.txt", FormatText, tt.method)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.want || len(chunks) == 0 {
+				t.Errorf("%d chunks by %s, want %s", len(chunks), name, tt.want)
+			}
+		})
+	}
+
+	t.Run("no chunks created", func(t *testing.T) {
+		_, _, err := NewFactory(Config{MaxChunkSize: 1000, SizeUnit: SizeRunes, Fallback: []string{"markdown"}}).Chunk(" \n\n ", "rules.txt", FormatText, "simple")
+		if err == nil || !strings.Contains(err.Error(), "all chunkers failed") {
+			t.Errorf("error %v, want all chunkers failed", err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	meta "github.com/yuin/goldmark-meta"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// FrontMatterPrefix — префикс полей front matter в Metadata чанков, чтобы они не пересекались
+// с метаданными chunker'ов (section, level, part)
+const FrontMatterPrefix = "doc_"
+
+// parseMarkdown разбирает markdown (GFM). Front matter к этому моменту уже отрезан фабрикой
+// (SplitFrontMatter). Расширение meta здесь не подключается: на блоке "---", который не YAML,
+// оно вставляет в документ комментарий с ошибкой, и тот попадает в текст чанков
+func parseMarkdown(content []byte) ast.Node {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	return md.Parser().Parse(text.NewReader(content))
+}
+
+// SplitFrontMatter отделяет YAML front matter от markdown-документа: возвращает текст без него
+// и поля (title, edition, jurisdiction ...), вложенные — через точку ("source.url").
+// Документ без front matter или с блоком, который не разбирается как YAML, возвращается как есть с пустыми полями
+func SplitFrontMatter(content string) (string, map[string]string, error) {
+	first, rest, ok := strings.Cut(content, "\n")
+	if !ok || strings.TrimSpace(first) != "---" {
+		return content, nil, nil
+	}
+	offset := len(first) + 1
+	for rest != "" {
+		line, tail, _ := strings.Cut(rest, "\n")
+		offset += len(line) + 1
+		rest = tail
+		if strings.TrimSpace(line) != "---" {
+			continue
+		}
+
+		block := content[:min(offset, len(content))]
+		body := content[min(offset, len(content)):]
+		md := goldmark.New(goldmark.WithExtensions(meta.Meta))
+		ctx := parser.NewContext()
+		md.Parser().Parse(text.NewReader([]byte(block)), parser.WithContext(ctx))
+
+		items, err := meta.TryGet(ctx)
+		if err != nil {
+			// Блок между "---" не YAML — это обычный текст между горизонтальными чертами, он остаётся в документе
+			return content, nil, fmt.Errorf("invalid front matter: %w", err)
+		}
+		fields := make(map[string]string)
+		for key, value := range items {
+			flattenFrontMatter(key, value, fields)
+		}
+		return body, fields, nil
+	}
+	// Незакрытый блок — не front matter, а обычная горизонтальная черта
+	return content, nil, nil
+}
+
+// flattenFrontMatter раскладывает значение YAML в плоские строки: списки через запятую, словари — ключами через точку
+func flattenFrontMatter(key string, value interface{}, out map[string]string) {
+	switch v := value.(type) {
+	case nil:
+	case map[interface{}]interface{}:
+		for k, item := range v {
+			flattenFrontMatter(fmt.Sprintf("%s.%v", key, k), item, out)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			flattenFrontMatter(key+"."+k, item, out)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		out[key] = strings.Join(items, ", ")
+	default:
+		out[key] = fmt.Sprint(v)
+	}
+}
+
+// withFrontMatter добавляет поля front matter в метаданные каждого чанка
+func withFrontMatter(chunks []Chunk, fields map[string]string) {
+	if len(fields) == 0 {
+		return
+	}
+	for i := range chunks {
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = make(map[string]string)
+		}
+		for key, value := range fields {
+			chunks[i].Metadata[FrontMatterPrefix+key] = value
+		}
+	}
+}
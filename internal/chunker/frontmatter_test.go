@@ -0,0 +1,67 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		content := "---\ntitle: Трудовой кодекс\nedition: 2024\n---\n\n# Глава 1\n"
+		body, fields, err := SplitFrontMatter(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "\n# Глава 1\n" {
+			t.Errorf("body %q", body)
+		}
+		if fields["title"] != "Трудовой кодекс" || fields["edition"] != "2024" {
+			t.Errorf("fields %v", fields)
+		}
+	})
+
+	// Текст между горизонтальными чертами, который не разбирается как YAML, не должен пропадать
+	t.Run("not yaml", func(t *testing.T) {
+		content := "---\nВнимание: порядок: изменён\n- пункт\n---\n\nТекст документа\n"
+		body, fields, err := SplitFrontMatter(content)
+		if err == nil {
+			t.Fatal("expected an error for a block that is not YAML")
+		}
+		if body != content {
+			t.Errorf("body %q, want the original content", body)
+		}
+		if len(fields) != 0 {
+			t.Errorf("fields %v, want none", fields)
+		}
+	})
+}
+
+// Блок "---", который не разбирается как YAML, остаётся текстом документа без сообщений парсера
+func TestFactoryKeepsMalformedFrontMatterAsText(t *testing.T) {
+	content := "---\nВнимание: порядок: изменён\n- пункт\n---\n\n## Глава 1\n\nТекст главы 1\n\n## Глава 2\n\nТекст главы 2\n"
+
+	config := Config{MaxChunkSize: 400, SizeUnit: SizeRunes}
+	chunks, _, err := NewFactory(config).Chunk(content, "doc.md", FormatMarkdown, "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all strings.Builder
+	for _, chunk := range chunks {
+		all.WriteString(chunk.Text + "\n")
+		for key := range chunk.Metadata {
+			if strings.HasPrefix(key, FrontMatterPrefix) {
+				t.Errorf("%s: unexpected front matter field %s", chunk.Section, key)
+			}
+		}
+	}
+	text := all.String()
+	if strings.Contains(text, "<!--") || strings.Contains(text, "yaml:") {
+		t.Errorf("parser message leaked into chunks: %q", text)
+	}
+	for _, want := range []string{"порядок: изменён", "Текст главы 1", "Текст главы 2"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("%q is lost: %q", want, text)
+		}
+	}
+}
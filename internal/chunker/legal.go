@@ -0,0 +1,436 @@
+package chunker
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// Строки структуры нормативного акта. Допускаем markdown-разметку заголовка (#, **),
+// чтобы chunker работал и с размеченными версиями кодексов. Номер без точки допустим только
+// в конце строки ("Статья 12"): "Статья 12 настоящего Кодекса ..." — ссылка в тексте, а не заголовок
+var (
+	reLegalCodePart = regexp.MustCompile(`^ЧАСТЬ\s+[А-ЯЁ]+$`)
+	reLegalDivision = regexp.MustCompile(`^(?i:раздел)\s+([IVXLCDM]+|\d+)(?:\.\s*(.*))?$`)
+	reLegalChapter  = regexp.MustCompile(`^(?i:глава)\s+(\d+(?:[.-]\d+)*)(?:\.\s*(.*))?$`)
+	reLegalArticle  = regexp.MustCompile(`^(?i:статья)\s+(\d+(?:[.-]\d+)*)(?:\.\s*(.*))?$`)
+	reLegalPoint    = regexp.MustCompile(`^(\d+(?:\.\d+)*\)|[а-яё]\))[\s\x{00A0}]`)
+	reHeadingMarkup = regexp.MustCompile(`^#{1,6}\s+|^\*\*|\*\*$`)
+	// Пометки об изменениях в конце абзаца, подряд
+	reTrailingNotes = regexp.MustCompile(`(?:` + reAmendmentNote.String() + `[\s\x{00A0}]*)+$`)
+)
+
+// legalSniffArticles — сколько строк "Статья N." должно встретиться, чтобы CHUNK_METHOD=auto выбрал legal chunker
+const legalSniffArticles = 5
+
+func init() {
+	Register(Registration{
+		Name:    "legal",
+		Formats: []string{FormatLegal},
+		Sniff:   LooksLikeLegalAct,
+		New:     func(config Config) (Chunker, error) { return NewLegalChunker(config), nil },
+	})
+}
+
+// LooksLikeLegalAct распознаёт кодексы и законы по заголовкам статей
+func LooksLikeLegalAct(content string) bool {
+	articles := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(reHeadingMarkup.ReplaceAllString(strings.TrimSpace(line), ""))
+		if reLegalArticle.MatchString(line) {
+			articles++
+			if articles >= legalSniffArticles {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LegalHeadingLevel возвращает уровень структурного заголовка нормативного акта: 1 — "ЧАСТЬ ПЕРВАЯ",
+// 2 — "Раздел I.", 3 — "Глава 1.", 4 — "Статья 1."; 0 — не заголовок. Разметка markdown (#, **) игнорируется.
+// Используется reader'ами PDF и HTML, чтобы распознавать те же заголовки, что и legal chunker
+func LegalHeadingLevel(line string) int {
+	line = strings.TrimSpace(reHeadingMarkup.ReplaceAllString(strings.TrimSpace(line), ""))
+	switch {
+	case reLegalCodePart.MatchString(line):
+		return 1
+	case reLegalDivision.MatchString(line):
+		return 2
+	case reLegalChapter.MatchString(line):
+		return 3
+	case reLegalArticle.MatchString(line):
+		return 4
+	}
+	return 0
+}
+
+// IsLegalHeading проверяет, что строка — заголовок части, раздела, главы или статьи
+func IsLegalHeading(line string) bool {
+	return LegalHeadingLevel(line) > 0
+}
+
+// LegalChunker разбивает тексты кодексов и законов по структуре ЧАСТЬ / Раздел / Глава / Статья:
+// один чанк на статью, длинные статьи делятся по частям
+type LegalChunker struct {
+	config Config
+}
+
+// NewLegalChunker создаёт новый chunker для нормативных актов
+func NewLegalChunker(config Config) *LegalChunker {
+	return &LegalChunker{config: config}
+}
+
+func (l *LegalChunker) Name() string {
+	return "legal"
+}
+
+// legalContext — текущее положение в структуре акта
+type legalContext struct {
+	codePart string // ЧАСТЬ ПЕРВАЯ
+	division string // Раздел I. ОБЩИЕ ПОЛОЖЕНИЯ
+	chapter  string // Глава 1. ОСНОВНЫЕ НАЧАЛА ...
+	chapterN string // 1
+}
+
+// legalArticle — статья (или текст вне статей) с накопленными абзацами
+type legalArticle struct {
+	ctx        legalContext
+	heading    string // Статья 1. Цели и задачи ...
+	number     string // 1
+	title      string // Цели и задачи ...
+	paragraphs []string
+}
+
+func (l *LegalChunker) Chunk(content, source string) ([]Chunk, error) {
+	var (
+		ctx      legalContext
+		articles []*legalArticle
+		current  *legalArticle
+		counts   = map[string]int{}
+	)
+
+	// Текст вне статей (преамбула, пояснения к главе) собираем в отдельный блок
+	startLoose := func() {
+		current = &legalArticle{ctx: ctx}
+		articles = append(articles, current)
+	}
+
+	for _, para := range SplitByParagraphs(content) {
+		line := strings.TrimSpace(reHeadingMarkup.ReplaceAllString(firstLine(para), ""))
+
+		switch {
+		case reLegalCodePart.MatchString(line):
+			counts["parts"]++
+			ctx = legalContext{codePart: line}
+			current = nil
+		case reLegalDivision.MatchString(line):
+			counts["divisions"]++
+			ctx.division = line
+			ctx.chapter, ctx.chapterN = "", ""
+			current = nil
+		case reLegalChapter.MatchString(line):
+			counts["chapters"]++
+			ctx.chapter = line
+			ctx.chapterN = reLegalChapter.FindStringSubmatch(line)[1]
+			current = nil
+		case reLegalArticle.MatchString(line):
+			counts["articles"]++
+			m := reLegalArticle.FindStringSubmatch(line)
+			current = &legalArticle{
+				ctx:     ctx,
+				heading: line,
+				number:  m[1],
+				title:   strings.TrimSpace(m[2]),
+			}
+			articles = append(articles, current)
+			// Заголовок статьи мог быть склеен с первым абзацем без пустой строки
+			if rest := strings.TrimSpace(strings.TrimPrefix(para, firstLine(para))); rest != "" {
+				current.paragraphs = append(current.paragraphs, rest)
+			}
+			continue
+		default:
+			if current == nil {
+				startLoose()
+			}
+			current.paragraphs = append(current.paragraphs, para)
+			continue
+		}
+
+		// Строка структуры могла содержать продолжение (редко, но бывает в выгрузках)
+		if rest := strings.TrimSpace(strings.TrimPrefix(para, firstLine(para))); rest != "" {
+			startLoose()
+			current.paragraphs = append(current.paragraphs, rest)
+		}
+	}
+
+	if counts["articles"] == 0 {
+		return nil, fmt.Errorf("legal chunker found no articles (\"Статья N.\" lines)")
+	}
+
+	log.Printf("📊 [%s] Document structure: parts=%d, divisions=%d, chapters=%d, articles=%d",
+		l.Name(), counts["parts"], counts["divisions"], counts["chapters"], counts["articles"])
+
+	var chunks []Chunk
+	for _, article := range articles {
+		if article.heading == "" && len(article.paragraphs) == 0 {
+			continue
+		}
+		chunks = append(chunks, l.chunkArticle(article, source)...)
+	}
+
+	log.Printf("✅ [%s] Created %d chunks", l.Name(), len(chunks))
+	return chunks, nil
+}
+
+// chunkArticle превращает статью в один чанк или, если она длинная, в несколько чанков по частям.
+// Каждый чанк начинается с заголовка статьи, чтобы его можно было процитировать отдельно.
+func (l *LegalChunker) chunkArticle(article *legalArticle, source string) []Chunk {
+	section := article.heading
+	if section == "" {
+		section = article.ctx.label()
+	}
+
+	full := strings.Join(article.paragraphs, "\n\n")
+	if article.heading != "" {
+		full = strings.TrimSpace(article.heading + "\n\n" + full)
+	}
+
+	if l.config.Size(l.withHeadingPath(full, article)) <= l.config.MaxChunkSize {
+		return []Chunk{CreateChunk(l.withHeadingPath(full, article), source, section, l.metadata(article, ""))}
+	}
+
+	// Заголовок статьи и путь до неё повторяются в каждом чанке — их размер вычитается из лимита.
+	// Если заголовок занимает почти весь лимит, текст всё равно режется не мельче четверти лимита
+	prefix := ""
+	if article.heading != "" {
+		prefix = article.heading + "\n\n"
+	}
+	budget := l.config.MaxChunkSize - l.config.Size(l.withHeadingPath(prefix, article))
+	budget = max(budget, l.config.MaxChunkSize/4)
+
+	// Части статьи — абзацы; пункты перечня "1) ..." и абзацы со строчной буквы
+	// относятся к предыдущей части
+	var parts [][]string
+	for _, para := range attachNotes(article.paragraphs, "\n") {
+		if len(parts) > 0 && isContinuation(para) {
+			parts[len(parts)-1] = append(parts[len(parts)-1], para)
+			continue
+		}
+		parts = append(parts, []string{para})
+	}
+
+	// Единица группировки — часть целиком, если она сама не влезает в лимит — её пункты,
+	// а слишком длинный пункт или абзац — куски из целых предложений
+	type unit struct {
+		part int
+		text string
+	}
+	var units []unit
+	for i, part := range parts {
+		partText := strings.Join(part, "\n\n")
+		if l.config.Size(partText) <= budget {
+			units = append(units, unit{part: i + 1, text: partText})
+			continue
+		}
+		for _, para := range part {
+			if l.config.Size(para) <= budget {
+				units = append(units, unit{part: i + 1, text: para})
+				continue
+			}
+			for _, piece := range l.splitParagraph(para, budget) {
+				units = append(units, unit{part: i + 1, text: piece})
+			}
+		}
+	}
+
+	var chunks []Chunk
+	var current strings.Builder
+	firstPart, lastPart := 0, 0
+	partNum := 1
+
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		text := current.String()
+		if article.heading != "" {
+			text = article.heading + "\n\n" + text
+		}
+		sectionWithPart := section
+		if partNum > 1 {
+			sectionWithPart = fmt.Sprintf("%s (часть %d)", section, partNum)
+		}
+		metadata := l.metadata(article, partsRange(firstPart, lastPart))
+		metadata["part"] = fmt.Sprintf("%d", partNum)
+		metadata["has_parts"] = "true"
+		chunks = append(chunks, CreateChunk(l.withHeadingPath(text, article), source, sectionWithPart, metadata))
+		current.Reset()
+		partNum++
+	}
+
+	for _, u := range units {
+		if current.Len() > 0 && l.config.Size(current.String()+"\n\n"+u.text) > budget {
+			flush()
+		}
+		if current.Len() == 0 {
+			firstPart = u.part
+		} else {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(u.text)
+		lastPart = u.part
+	}
+	flush()
+
+	return chunks
+}
+
+// metadata собирает метаданные статьи. Ключ "section" не используем — он занят названием секции чанка,
+// поэтому раздел кодекса хранится как "division"
+func (l *LegalChunker) metadata(article *legalArticle, parts string) map[string]string {
+	metadata := map[string]string{
+		"method": "legal-article",
+	}
+	if article.number != "" {
+		metadata["article"] = article.number
+		metadata["article_title"] = article.title
+	}
+	if article.ctx.chapter != "" {
+		metadata["chapter"] = article.ctx.chapter
+		metadata["chapter_num"] = article.ctx.chapterN
+	}
+	if article.ctx.division != "" {
+		metadata["division"] = article.ctx.division
+	}
+	if article.ctx.codePart != "" {
+		metadata["code_part"] = article.ctx.codePart
+	}
+	if parts != "" {
+		metadata["article_parts"] = parts
+	}
+	if path := article.path(); len(path) > 0 {
+		metadata["heading_path"] = strings.Join(path, headingPathSeparator)
+	}
+	return metadata
+}
+
+// path возвращает путь в структуре акта: ЧАСТЬ > Раздел > Глава > Статья
+func (a *legalArticle) path() []string {
+	var path []string
+	for _, p := range []string{a.ctx.codePart, a.ctx.division, a.ctx.chapter, a.heading} {
+		if p != "" {
+			path = append(path, p)
+		}
+	}
+	return path
+}
+
+// withHeadingPath добавляет в начало текста путь до статьи (без самой статьи — её заголовок уже в тексте),
+// если это включено в конфигурации
+func (l *LegalChunker) withHeadingPath(text string, article *legalArticle) string {
+	if !l.config.PrependHeadingPath {
+		return text
+	}
+	path := article.path()
+	if article.heading != "" {
+		path = path[:len(path)-1]
+	}
+	if len(path) == 0 {
+		return text
+	}
+	return strings.Join(path, headingPathSeparator) + "\n\n" + text
+}
+
+// label возвращает наиболее точное название места в структуре акта
+func (c legalContext) label() string {
+	switch {
+	case c.chapter != "":
+		return c.chapter
+	case c.division != "":
+		return c.division
+	case c.codePart != "":
+		return c.codePart
+	default:
+		return "Преамбула"
+	}
+}
+
+// attachNotes приклеивает абзацы и предложения из одних пометок об изменениях ("(Дополнение частью -
+// Федеральный закон ...)") к предыдущему, а пометки в самом начале ("(Наименование в редакции ...)") —
+// к следующему. Иначе пометка становится отдельной частью статьи, а после ExtractAmendments
+// от такого чанка остаётся один заголовок. sep — разделитель при склейке
+func attachNotes(items []string, sep string) []string {
+	result := make([]string, 0, len(items))
+	leading := ""
+	for _, item := range items {
+		if isNoteOnly(item) {
+			if len(result) > 0 {
+				result[len(result)-1] += sep + strings.TrimSpace(item)
+			} else {
+				leading += strings.TrimSpace(item) + sep
+			}
+			continue
+		}
+		result = append(result, leading+item)
+		leading = ""
+	}
+	if leading != "" {
+		result = append(result, strings.TrimSpace(leading))
+	}
+	return result
+}
+
+// splitParagraph режет абзац длиннее budget на куски из целых предложений. Пометки об изменениях в конце абзаца
+// отделяются до резки по словам и добавляются к последнему куску: иначе кусок из одних пометок после
+// ExtractAmendments превратился бы в чанк с одним заголовком. Без ExtractAmendments пометки остаются текстом
+// и, если не помещаются в последний кусок, становятся отдельными кусками
+func (l *LegalChunker) splitParagraph(para string, budget int) []string {
+	body, notes := para, ""
+	if loc := reTrailingNotes.FindStringIndex(para); loc != nil && loc[0] > 0 {
+		body, notes = para[:loc[0]], strings.Join(strings.Fields(para[loc[0]:]), " ")
+	}
+
+	pieces := l.config.packSentences(attachNotes(SplitSentences(body), " "), budget)
+	switch {
+	case notes == "":
+	case len(pieces) > 0 && (l.config.ExtractAmendments || l.config.Size(pieces[len(pieces)-1]+" "+notes) <= budget):
+		pieces[len(pieces)-1] += " " + notes
+	default:
+		pieces = append(pieces, l.config.packSentences([]string{notes}, budget)...)
+	}
+	return pieces
+}
+
+// isNoteOnly проверяет, что текст состоит только из пометок об изменениях
+func isNoteOnly(text string) bool {
+	return strings.TrimSpace(reAmendmentNote.ReplaceAllString(text, "")) == ""
+}
+
+// isContinuation определяет, продолжает ли абзац предыдущую часть статьи (пункт перечня)
+func isContinuation(para string) bool {
+	if reLegalPoint.MatchString(para) {
+		return true
+	}
+	for _, r := range para {
+		return r >= 'а' && r <= 'я' || r == 'ё'
+	}
+	return false
+}
+
+// partsRange форматирует диапазон частей статьи: "2" или "2-4"
+func partsRange(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("%d", first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
+
+// firstLine возвращает первую строку текста
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
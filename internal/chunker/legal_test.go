@@ -0,0 +1,202 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Пометки "(Дополнение частью - ...)" отдельными абзацами и в конце длинного абзаца не должны
+// превращаться в части статьи, от которых после ExtractAmendments остаётся один заголовок
+func TestLegalChunkerKeepsAmendmentNotesWithTheirParts(t *testing.T) {
+	heading := "Статья 5. Особенности регулирования труда работников отдельных категорий организаций"
+	sentence := "Работодатель обязан обеспечить работнику условия труда, предусмотренные трудовым договором. "
+	content := strings.Join([]string{
+		heading,
+		strings.Repeat(sentence, 2),
+		"(Дополнение частью - Федеральный закон от 13.12.2024 № 470-ФЗ)",
+		strings.Repeat(sentence, 2),
+		strings.TrimSpace(strings.Repeat(sentence, 4)) + " (Дополнение частью - Федеральный закон от 14.07.2022 № 273-ФЗ)",
+		"(Статья в редакции Федерального закона от 30.06.2006 № 90-ФЗ)",
+	}, "\n\n")
+
+	config := Config{MaxChunkSize: 300, MinChunkSize: 64, SizeUnit: SizeRunes, ExtractAmendments: true}
+	chunks, _, err := NewFactory(config).Chunk(content, "codex.md", FormatMarkdown, "legal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the article split into parts", len(chunks))
+	}
+
+	var notes []string
+	for _, chunk := range chunks {
+		if body := strings.TrimSpace(strings.TrimPrefix(chunk.Text, heading)); body == "" {
+			t.Errorf("%s: only the article heading is left", chunk.Section)
+		}
+		if size := config.Size(chunk.Text); size > config.MaxChunkSize {
+			t.Errorf("%s: %d runes, limit %d", chunk.Section, size, config.MaxChunkSize)
+		}
+		if strings.Contains(chunk.Text, "Федеральн") {
+			t.Errorf("%s: amendment note left in text: %q", chunk.Section, chunk.Text)
+		}
+		if n := chunk.Metadata["amendment_notes"]; n != "" {
+			notes = append(notes, n)
+		}
+	}
+
+	all := strings.Join(notes, "\n")
+	for _, law := range []string{"470-ФЗ", "273-ФЗ", "90-ФЗ"} {
+		if !strings.Contains(all, law) {
+			t.Errorf("note about %s is lost", law)
+		}
+	}
+}
+
+func TestLegalChunkerStructure(t *testing.T) {
+	content := strings.Join([]string{
+		"Настоящий Кодекс принят Государственной Думой.",
+		"ЧАСТЬ ПЕРВАЯ",
+		"Раздел I. ОБЩИЕ ПОЛОЖЕНИЯ",
+		"Глава 1. ОСНОВНЫЕ НАЧАЛА",
+		"Статья 1. Цели и задачи",
+		"Целями законодательства являются гарантии прав работников. Статья 12 настоящего Кодекса применяется к ним.",
+		"Статья 2\nОсновные принципы регулируются настоящей статьёй.",
+		"Глава 2. СИСТЕМА ЗАКОНОДАТЕЛЬСТВА",
+		"## Статья 3. Запрещение дискриминации",
+		"Каждый имеет равные возможности.",
+	}, "\n\n")
+
+	chunks, err := NewLegalChunker(Config{MaxChunkSize: 1000, SizeUnit: SizeRunes}).Chunk(content, "codex.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		section  string
+		article  string
+		chapter  string
+		path     string
+		contains string
+	}{
+		{"Преамбула", "", "", "", "принят Государственной Думой"},
+		{"Статья 1. Цели и задачи", "1", "1", "ЧАСТЬ ПЕРВАЯ > Раздел I. ОБЩИЕ ПОЛОЖЕНИЯ > Глава 1. ОСНОВНЫЕ НАЧАЛА > Статья 1. Цели и задачи", "Статья 12 настоящего Кодекса"},
+		{"Статья 2", "2", "1", "ЧАСТЬ ПЕРВАЯ > Раздел I. ОБЩИЕ ПОЛОЖЕНИЯ > Глава 1. ОСНОВНЫЕ НАЧАЛА > Статья 2", "Основные принципы"},
+		{"Статья 3. Запрещение дискриминации", "3", "2", "ЧАСТЬ ПЕРВАЯ > Раздел I. ОБЩИЕ ПОЛОЖЕНИЯ > Глава 2. СИСТЕМА ЗАКОНОДАТЕЛЬСТВА > Статья 3. Запрещение дискриминации", "равные возможности"},
+	}
+	if len(chunks) != len(tests) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(tests))
+	}
+	for i, tt := range tests {
+		chunk := chunks[i]
+		if chunk.Section != tt.section {
+			t.Errorf("chunk %d: section %q, want %q", i, chunk.Section, tt.section)
+		}
+		if chunk.Metadata["article"] != tt.article || chunk.Metadata["chapter_num"] != tt.chapter {
+			t.Errorf("%s: article %q, chapter %q", tt.section, chunk.Metadata["article"], chunk.Metadata["chapter_num"])
+		}
+		if chunk.Metadata["heading_path"] != tt.path {
+			t.Errorf("%s: heading_path %q", tt.section, chunk.Metadata["heading_path"])
+		}
+		if !strings.Contains(chunk.Text, tt.contains) {
+			t.Errorf("%s: text %q lacks %q", tt.section, chunk.Text, tt.contains)
+		}
+		if tt.article != "" && !strings.HasPrefix(chunk.Text, tt.section) {
+			t.Errorf("%s: text does not start with the article heading", tt.section)
+		}
+	}
+}
+
+// Длинная статья делится по частям; пункты "1)", "а)" и абзацы со строчной буквы остаются со своей частью,
+// слишком длинная часть режется по предложениям. Каждый кусок начинается с заголовка статьи
+func TestLegalChunkerSplitsOversizedArticle(t *testing.T) {
+	heading := "Статья 81. Расторжение трудового договора по инициативе работодателя"
+	sentence := "Работодатель обязан предупредить работника в письменной форме под подпись. "
+	content := strings.Join([]string{
+		heading,
+		"Трудовой договор может быть расторгнут работодателем в случаях:",
+		"1) ликвидации организации;",
+		"2) сокращения численности работников;",
+		"а) с согласия профсоюза;",
+		"а также в иных случаях.",
+		strings.TrimSpace(strings.Repeat(sentence, 3)),
+		strings.TrimSpace(strings.Repeat(sentence, 12)),
+	}, "\n\n")
+
+	config := Config{MaxChunkSize: 400, SizeUnit: SizeRunes}
+	chunks, err := NewLegalChunker(config).Chunk(content, "codex.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want the article split", len(chunks))
+	}
+
+	var parts []string
+	for i, chunk := range chunks {
+		if size := config.Size(chunk.Text); size > config.MaxChunkSize {
+			t.Errorf("%s: %d runes, limit %d", chunk.Section, size, config.MaxChunkSize)
+		}
+		if !strings.HasPrefix(chunk.Text, heading+"\n\n") {
+			t.Errorf("%s: no article heading", chunk.Section)
+		}
+		if chunk.Metadata["article"] != "81" || chunk.Metadata["has_parts"] != "true" {
+			t.Errorf("%s: metadata %v", chunk.Section, chunk.Metadata)
+		}
+		if want := fmt.Sprintf("%d", i+1); chunk.Metadata["part"] != want {
+			t.Errorf("%s: part %q, want %s", chunk.Section, chunk.Metadata["part"], want)
+		}
+		parts = append(parts, chunk.Metadata["article_parts"])
+	}
+
+	first := chunks[0].Text
+	for _, point := range []string{"1) ликвидации", "2) сокращения", "а) с согласия", "а также в иных"} {
+		if !strings.Contains(first, point) {
+			t.Errorf("point %q is not with its part: %q", point, first)
+		}
+	}
+	if parts[0] != "1" || parts[len(parts)-1] != "3" {
+		t.Errorf("article_parts %v, want from 1 to 3", parts)
+	}
+	if chunks[1].Section != heading+" (часть 2)" {
+		t.Errorf("section %q", chunks[1].Section)
+	}
+}
+
+// От утратившей силу статьи остаются заголовок "Статья N." и пометка: такой чанк помечается repealed,
+// соседние статьи — нет
+func TestLegalChunkerRepealedArticleStubs(t *testing.T) {
+	content := strings.Join([]string{
+		"Глава 1. ОБЩИЕ ПОЛОЖЕНИЯ",
+		"Статья 6. Разграничение полномочий",
+		"Полномочия разграничиваются настоящим Кодексом.",
+		"Статья 7.",
+		"(Статья утратила силу - Федеральный закон от 22.08.2004 № 122-ФЗ)",
+		"Статья 8. Локальные нормативные акты",
+		"Работодатель принимает локальные нормативные акты. (В редакции Федерального закона от 30.06.2006 № 90-ФЗ)",
+		"Статья 9. (Утратила силу - Федеральный закон от 30.06.2006 № 90-ФЗ)",
+	}, "\n\n")
+
+	config := Config{MaxChunkSize: 1000, SizeUnit: SizeRunes, ExtractAmendments: true}
+	chunks, _, err := NewFactory(config).Chunk(content, "codex.txt", FormatText, "legal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"6": "false", "7": "true", "8": "false", "9": "true"}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want one per article", len(chunks))
+	}
+	for _, chunk := range chunks {
+		article := chunk.Metadata["article"]
+		if got := chunk.Metadata["repealed"]; got != want[article] {
+			t.Errorf("article %s: repealed=%s, want %s", article, got, want[article])
+		}
+		if strings.Contains(chunk.Text, "Федеральн") {
+			t.Errorf("article %s: note left in text %q", article, chunk.Text)
+		}
+	}
+	if chunks[2].Metadata["amended_by"] != "90-ФЗ от 30.06.2006" {
+		t.Errorf("article 8: amended_by %q", chunks[2].Metadata["amended_by"])
+	}
+}
@@ -5,11 +5,19 @@ import (
 	"log"
 	"strings"
 
-	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/text"
 )
 
+func init() {
+	Register(Registration{
+		Name:       "markdown",
+		Aliases:    []string{"md"},
+		Extensions: []string{".md", ".markdown"},
+		Formats:    []string{FormatMarkdown},
+		New:        func(config Config) (Chunker, error) { return NewMarkdownChunker(config), nil },
+	})
+}
+
 // MarkdownChunker разбивает markdown документы с адаптивным выбором стратегии
 type MarkdownChunker struct {
 	config Config
@@ -33,37 +41,46 @@ type DocumentStructure struct {
 
 // ChunkingStrategy определяет стратегию разбиения
 type ChunkingStrategy struct {
-	Level int // уровень заголовка (2-4)
+	Level  int    // уровень заголовка (1-6), 0 — по параграфам
+	Reason string // почему выбрана стратегия — для лога
 }
 
+// Стратегии выбора уровня заголовков
+const (
+	HeadingStrategyFirst = "first" // первый уровень от HeadingMinLevel, где достаточно заголовков
+	HeadingStrategyAuto  = "auto"  // уровень, секции которого ближе всего к MaxChunkSize
+)
+
 func (m *MarkdownChunker) Chunk(content, source string) ([]Chunk, error) {
-	md := goldmark.New()
-	reader := text.NewReader([]byte(content))
-	doc := md.Parser().Parse(reader)
+	// GFM: таблицы, зачёркивание, чекбоксы, автоссылки
+	doc := parseMarkdown([]byte(content))
 
 	// Анализируем структуру документа
 	structure := m.analyzeStructure(doc)
 
+	log.Printf("📊 [%s] Document structure: headings=%v, paragraphs=%d",
+		m.Name(), structure.HeadingCounts, structure.TotalParagraphs)
+
 	// Выбираем стратегию разбиения
-	strategy, err := m.selectStrategy(structure)
+	strategy, err := m.selectStrategy(doc, []byte(content), structure)
 	if err != nil {
 		// Явно возвращаем ошибку - пусть вызывающий код решает что делать
 		return nil, fmt.Errorf("markdown chunker cannot process this content: %w", err)
 	}
 
-	log.Printf("📊 [%s] Document structure: headings=%v, paragraphs=%d",
-		m.Name(), structure.HeadingCounts, structure.TotalParagraphs)
-
 	var chunks []Chunk
 	if strategy.Level == 0 {
 		// Разбиваем по параграфам
-		log.Printf("🎯 [%s] Selected strategy: paragraphs (AST)", m.Name())
+		log.Printf("🎯 [%s] Selected strategy: paragraphs (AST) — %s", m.Name(), strategy.Reason)
 
 		chunks = m.chunkByParagraphsAST(doc, []byte(content), source)
 	} else {
 		// Применяем стратегию разбиения по заголовкам
-		log.Printf("🎯 [%s] Selected strategy: heading (level %d)", m.Name(), strategy.Level)
+		log.Printf("🎯 [%s] Selected strategy: heading (level %d) — %s", m.Name(), strategy.Level, strategy.Reason)
 		chunks = m.chunkByHeadings(doc, []byte(content), source, strategy.Level)
+		for i := range chunks {
+			chunks[i].Metadata["method"] = fmt.Sprintf("headings-h%d", strategy.Level)
+		}
 	}
 
 	log.Printf("✅ [%s] Created %d chunks", m.Name(), len(chunks))
@@ -91,29 +108,89 @@ func (m *MarkdownChunker) analyzeStructure(doc ast.Node) DocumentStructure {
 	return structure
 }
 
-// selectStrategy выбирает уровень заголовков для разбиения
-func (m *MarkdownChunker) selectStrategy(structure DocumentStructure) (ChunkingStrategy, error) {
-	// Проверяем заголовки от H2 до H4 (наиболее частые для структурированных документов)
-	for level := 2; level <= 4; level++ {
+// headingLevels возвращает диапазон уровней-кандидатов (по умолчанию H2–H4)
+func (m *MarkdownChunker) headingLevels() (int, int) {
+	from, to := m.config.HeadingMinLevel, m.config.HeadingMaxLevel
+	if from <= 0 {
+		from = 2
+	}
+	if to <= 0 {
+		to = 4
+	}
+	return max(from, 1), min(to, 6)
+}
+
+// minHeadings возвращает минимальное число заголовков уровня, чтобы по нему разбивать
+func (m *MarkdownChunker) minHeadings(level int) int {
+	if n, ok := m.config.MinHeadings[level]; ok {
+		return n
+	}
+	switch {
+	case level <= 2:
+		return 3 // Для H1/H2 (статьи) достаточно 3
+	case level == 3:
+		return 5 // Для H3 (подразделы) нужно больше
+	default:
+		return 10 // Для H4 и глубже нужно ещё больше
+	}
+}
+
+// selectStrategy выбирает уровень заголовков для разбиения. Если в диапазоне HeadingMinLevel–HeadingMaxLevel
+// ни один уровень не подошёл, а H1 достаточно, разбиение идёт по H1
+func (m *MarkdownChunker) selectStrategy(doc ast.Node, content []byte, structure DocumentStructure) (ChunkingStrategy, error) {
+	from, to := m.headingLevels()
+	auto := m.config.HeadingStrategy == HeadingStrategyAuto
+
+	best := ChunkingStrategy{}
+	bestScore := 0.0
+	var rejected []string
+	for level := from; level <= to; level++ {
 		count := structure.HeadingCounts[level]
-		// Если есть достаточно заголовков этого уровня - используем их
-		minHeadings := 3
-		switch level {
-		case 2:
-			minHeadings = 3 // Для H2 (статьи) достаточно 3
-		case 3:
-			minHeadings = 5 // Для H3 (подразделы) нужно больше
-		default:
-			minHeadings = 10 // Для H4 нужно ещё больше
+		minHeadings := m.minHeadings(level)
+		if count < minHeadings {
+			if count > 0 {
+				rejected = append(rejected, fmt.Sprintf("h%d: %d < %d", level, count, minHeadings))
+			}
+			continue
+		}
+
+		if !auto {
+			// Если есть достаточно заголовков этого уровня - используем их
+			best.Level = level
+			best.Reason = fmt.Sprintf("first level in h%d–h%d with ≥%d headings (found %d)", from, to, minHeadings, count)
+			break
 		}
 
-		if count >= minHeadings {
-			return ChunkingStrategy{Level: level}, nil
+		score, oversized, sections := m.scoreLevel(doc, content, level)
+		log.Printf("🧮 [%s] h%d: %d sections, %d over limit, score %.3f", m.Name(), level, sections, oversized, score)
+		if best.Level == 0 || score < bestScore {
+			best.Level, bestScore = level, score
+			best.Reason = fmt.Sprintf("lowest section size deviation from max chunk size among h%d–h%d (score %.3f, %d sections, %d over limit)",
+				from, to, score, sections, oversized)
+		}
+	}
+
+	if best.Level > 0 {
+		return best, nil
+	}
+
+	// Документ со статьёй на каждый H1 не должен уходить в разбиение по параграфам из-за диапазона H2–H4
+	if from > 1 {
+		if count, minHeadings := structure.HeadingCounts[1], m.minHeadings(1); count >= minHeadings {
+			reason := fmt.Sprintf("no heading level in h%d–h%d has enough headings, falling back to h1 (found %d ≥ %d)", from, to, count, minHeadings)
+			if len(rejected) > 0 {
+				reason += " (" + strings.Join(rejected, ", ") + ")"
+			}
+			return ChunkingStrategy{Level: 1, Reason: reason}, nil
 		}
 	}
 
 	if structure.TotalParagraphs >= 2 {
-		return ChunkingStrategy{Level: 0}, nil // Level=0 означает "по параграфам"
+		reason := fmt.Sprintf("no heading level in h%d–h%d has enough headings", from, to)
+		if len(rejected) > 0 {
+			reason += " (" + strings.Join(rejected, ", ") + ")"
+		}
+		return ChunkingStrategy{Level: 0, Reason: reason}, nil // Level=0 означает "по параграфам"
 	}
 
 	// Нет подходящей markdown структуры - возвращаем ошибку
@@ -123,21 +200,58 @@ func (m *MarkdownChunker) selectStrategy(structure DocumentStructure) (ChunkingS
 	)
 }
 
-func (m *MarkdownChunker) chunkByParagraphsAST(doc ast.Node, content []byte, source string) []Chunk {
-	var paragraphs []string
+// scoreLevel оценивает разбиение по заголовкам уровня: средний квадрат отклонения размера секции
+// от MaxChunkSize в долях MaxChunkSize. Мелкие секции дают до 1, секции больше лимита
+// (их придётся резать по параграфам) — квадратично растущий штраф. Меньше — лучше
+func (m *MarkdownChunker) scoreLevel(doc ast.Node, content []byte, level int) (score float64, oversized, sections int) {
+	limit := float64(max(m.config.MaxChunkSize, 1))
+	var current strings.Builder
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		size := m.config.Size(strings.TrimSpace(current.String()))
+		if size > m.config.MaxChunkSize {
+			oversized++
+		}
+		deviation := (float64(size) - limit) / limit
+		score += deviation * deviation
+		sections++
+		current.Reset()
+	}
 
-	// Собираем параграфы из AST — каждый параграф гарантированно целый
-	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if entering {
-			if p, ok := n.(*ast.Paragraph); ok {
-				text := string(p.Text(content))
-				if strings.TrimSpace(text) != "" {
-					paragraphs = append(paragraphs, text)
-				}
+	// Секции собираются так же, как в chunkByHeadings
+	for block := doc.FirstChild(); block != nil; block = block.NextSibling() {
+		heading, ok := block.(*ast.Heading)
+		if !ok {
+			if text := renderBlock(block, content); strings.TrimSpace(text) != "" {
+				current.WriteString(text)
+				current.WriteString("\n\n")
 			}
+			continue
 		}
-		return ast.WalkContinue, nil
-	})
+		if heading.Level <= level {
+			flush()
+		}
+		current.WriteString(renderInline(heading, content) + "\n\n")
+	}
+	flush()
+
+	if sections == 0 {
+		return 0, 0, 0
+	}
+	return score / float64(sections), oversized, sections
+}
+
+func (m *MarkdownChunker) chunkByParagraphsAST(doc ast.Node, content []byte, source string) []Chunk {
+	var paragraphs []string
+
+	// Собираем блоки верхнего уровня из AST — каждый блок гарантированно целый,
+	// списки и таблицы сохраняют маркеры и строки
+	for block := doc.FirstChild(); block != nil; block = block.NextSibling() {
+		paragraphs = append(paragraphs, m.blockUnits(block, content)...)
+	}
+	paragraphs = m.fitBlocks(paragraphs)
 
 	// Группируем параграфы в чанки до MaxChunkSize
 	var chunks []Chunk
@@ -146,7 +260,7 @@ func (m *MarkdownChunker) chunkByParagraphsAST(doc ast.Node, content []byte, sou
 	var prevParagraphs []string // для overlap — целые параграфы!
 
 	for _, para := range paragraphs {
-		if currentPart.Len() > 0 && currentPart.Len()+len(para) > m.config.MaxChunkSize {
+		if currentPart.Len() > 0 && m.config.Size(currentPart.String()+"\n\n"+para) > m.config.MaxChunkSize {
 			// Сохраняем чанк
 			chunks = append(chunks, CreateChunk(currentPart.String(), source,
 				fmt.Sprintf("Чанк %d", chunkNum), map[string]string{
@@ -156,8 +270,7 @@ func (m *MarkdownChunker) chunkByParagraphsAST(doc ast.Node, content []byte, sou
 			currentPart.Reset()
 
 			// Overlap: берём последние 1-2 целых параграфа, а не N символов
-			overlapParas := m.selectOverlapParagraphs(prevParagraphs)
-			for _, op := range overlapParas {
+			for _, op := range m.overlapFor(prevParagraphs, para) {
 				currentPart.WriteString(op)
 				currentPart.WriteString("\n\n")
 			}
@@ -195,70 +308,87 @@ func (m *MarkdownChunker) selectOverlapParagraphs(recentParas []string) []string
 
 	// Берём параграфы с конца, пока не превысим Overlap по размеру
 	var result []string
-	totalLen := 0
+	totalSize := 0
 	for i := len(recentParas) - 1; i >= 0; i-- {
-		if totalLen+len(recentParas[i]) > m.config.Overlap {
+		size := m.config.Size(recentParas[i])
+		if totalSize+size > m.config.Overlap {
 			break
 		}
 		result = append([]string{recentParas[i]}, result...)
-		totalLen += len(recentParas[i])
+		totalSize += size
 	}
 	return result
 }
 
+// overlapFor выбирает параграфы перекрытия перед next; если вместе с next они не помещаются в чанк — без перекрытия
+func (m *MarkdownChunker) overlapFor(recentParas []string, next string) []string {
+	overlap := m.selectOverlapParagraphs(recentParas)
+	if len(overlap) > 0 && m.config.Size(strings.Join(overlap, "\n\n")+"\n\n"+next) > m.config.MaxChunkSize {
+		return nil
+	}
+	return overlap
+}
+
+// headingEntry — заголовок в пути от корня документа до чанка
+type headingEntry struct {
+	level int
+	text  string
+}
+
+// headingPathSeparator разделяет уровни в "Раздел I > Глава 1 > Статья 3"
+const headingPathSeparator = " > "
+
 // chunkByHeadings разбивает документ по заголовкам указанного уровня
 func (m *MarkdownChunker) chunkByHeadings(doc ast.Node, content []byte, source string, targetLevel int) []Chunk {
 	var chunks []Chunk
 	var currentChunk strings.Builder
 	var currentSection string
-	var parentSection string // Для контекста подразделов
 	var currentLevel int
+	var stack []headingEntry // полный стек заголовков до текущего места
+	var currentPath []headingEntry
+
+	for block := doc.FirstChild(); block != nil; block = block.NextSibling() {
+		heading, ok := block.(*ast.Heading)
+		if !ok {
+			if text := renderBlock(block, content); strings.TrimSpace(text) != "" {
+				currentChunk.WriteString(text)
+				currentChunk.WriteString("\n\n")
+			}
+			continue
+		}
 
-	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if entering {
-			if heading, ok := n.(*ast.Heading); ok {
-				headingText := extractText(heading, content)
-
-				// Если встретили заголовок целевого уровня или выше - начинаем новый чанк
-				if heading.Level <= targetLevel {
-					if currentChunk.Len() > 0 {
-						// Сохраняем предыдущий чанк
-						chunks = append(chunks, m.finalizeChunk(
-							currentChunk.String(),
-							source,
-							currentSection,
-							parentSection,
-							currentLevel,
-						)...)
-						currentChunk.Reset()
-					}
-
-					currentSection = headingText
-					currentLevel = heading.Level
-
-					// Обновляем родительскую секцию
-					if heading.Level == targetLevel {
-						parentSection = headingText
-					}
-
-					currentChunk.WriteString(headingText + "\n\n")
-				} else {
-					// Подзаголовки включаем в текущий чанк
-					currentChunk.WriteString("\n" + headingText + "\n\n")
-				}
-			} else if textNode, ok := n.(*ast.Text); ok {
-				// Пропускаем Text-узлы внутри Heading — они уже записаны как headingText
-				if _, isHeading := textNode.Parent().(*ast.Heading); !isHeading {
-					currentChunk.Write(textNode.Segment.Value(content))
-				}
+		headingText := renderInline(heading, content)
+
+		// Заголовок закрывает все заголовки своего уровня и глубже
+		for len(stack) > 0 && stack[len(stack)-1].level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, headingEntry{level: heading.Level, text: headingText})
+
+		// Если встретили заголовок целевого уровня или выше - начинаем новый чанк
+		if heading.Level <= targetLevel {
+			if currentChunk.Len() > 0 {
+				// Сохраняем предыдущий чанк
+				chunks = append(chunks, m.finalizeChunk(
+					currentChunk.String(),
+					source,
+					currentSection,
+					currentPath,
+					currentLevel,
+				)...)
+				currentChunk.Reset()
 			}
+
+			currentSection = headingText
+			currentLevel = heading.Level
+			currentPath = append([]headingEntry(nil), stack...)
+
+			currentChunk.WriteString(headingText + "\n\n")
 		} else {
-			if _, ok := n.(*ast.Paragraph); ok {
-				currentChunk.WriteString("\n\n")
-			}
+			// Подзаголовки включаем в текущий чанк
+			currentChunk.WriteString("\n" + headingText + "\n\n")
 		}
-		return ast.WalkContinue, nil
-	})
+	}
 
 	// Сохраняем последний чанк
 	if currentChunk.Len() > 0 {
@@ -266,7 +396,7 @@ func (m *MarkdownChunker) chunkByHeadings(doc ast.Node, content []byte, source s
 			currentChunk.String(),
 			source,
 			currentSection,
-			parentSection,
+			currentPath,
 			currentLevel,
 		)...)
 	}
@@ -275,26 +405,21 @@ func (m *MarkdownChunker) chunkByHeadings(doc ast.Node, content []byte, source s
 }
 
 // finalizeChunk обрабатывает чанк: разбивает если большой, добавляет overlap для подразделов
-func (m *MarkdownChunker) finalizeChunk(text, source, section, parentSection string, level int) []Chunk {
+func (m *MarkdownChunker) finalizeChunk(text, source, section string, path []headingEntry, level int) []Chunk {
 	text = strings.TrimSpace(text)
 
 	// Если чанк меньше лимита - возвращаем как есть
-	if len(text) <= m.config.MaxChunkSize {
-		metadata := map[string]string{
-			"level": fmt.Sprintf("%d", level),
-		}
-		if parentSection != "" && parentSection != section {
-			metadata["parent_section"] = parentSection
-		}
-		return []Chunk{CreateChunk(text, source, section, metadata)}
+	if m.config.Size(text) <= m.config.MaxChunkSize {
+		metadata := headingMetadata(path, level)
+		return []Chunk{CreateChunk(m.withHeadingPath(text, path, false), source, section, metadata)}
 	}
 
 	// Разбиваем большой чанк на части по параграфам
-	return m.splitLargeChunk(text, source, section, parentSection, level)
+	return m.splitLargeChunk(text, source, section, path, level)
 }
 
-func (m *MarkdownChunker) splitLargeChunk(text, source, section, parentSection string, level int) []Chunk {
-	paragraphs := SplitByParagraphs(text)
+func (m *MarkdownChunker) splitLargeChunk(text, source, section string, path []headingEntry, level int) []Chunk {
+	paragraphs := m.fitBlocks(SplitByParagraphs(text))
 	var chunks []Chunk
 	var currentPart strings.Builder
 	partNum := 1
@@ -303,7 +428,7 @@ func (m *MarkdownChunker) splitLargeChunk(text, source, section, parentSection s
 	useOverlap := level > 2 && m.config.Overlap > 0
 
 	for _, para := range paragraphs {
-		if currentPart.Len() > 0 && currentPart.Len()+len(para) > m.config.MaxChunkSize {
+		if currentPart.Len() > 0 && m.config.Size(currentPart.String()+"\n\n"+para) > m.config.MaxChunkSize {
 			partText := currentPart.String()
 
 			sectionWithPart := section
@@ -311,22 +436,17 @@ func (m *MarkdownChunker) splitLargeChunk(text, source, section, parentSection s
 				sectionWithPart = fmt.Sprintf("%s (часть %d)", section, partNum)
 			}
 
-			metadata := map[string]string{
-				"level":     fmt.Sprintf("%d", level),
-				"part":      fmt.Sprintf("%d", partNum),
-				"has_parts": "true",
-			}
-			if parentSection != "" && parentSection != section {
-				metadata["parent_section"] = parentSection
-			}
+			metadata := headingMetadata(path, level)
+			metadata["part"] = fmt.Sprintf("%d", partNum)
+			metadata["has_parts"] = "true"
 
-			chunks = append(chunks, CreateChunk(partText, source, sectionWithPart, metadata))
+			// Со второй части заголовка секции в тексте уже нет — добавляем его в путь
+			chunks = append(chunks, CreateChunk(m.withHeadingPath(partText, path, partNum > 1), source, sectionWithPart, metadata))
 
 			currentPart.Reset()
 
 			if useOverlap {
-				overlapParas := m.selectOverlapParagraphs(prevParagraphs)
-				for _, op := range overlapParas {
+				for _, op := range m.overlapFor(prevParagraphs, para) {
 					currentPart.WriteString(op)
 					currentPart.WriteString("\n\n")
 				}
@@ -354,30 +474,94 @@ func (m *MarkdownChunker) splitLargeChunk(text, source, section, parentSection s
 			sectionWithPart = fmt.Sprintf("%s (часть %d)", section, partNum)
 		}
 
-		metadata := map[string]string{
-			"level": fmt.Sprintf("%d", level),
-		}
+		metadata := headingMetadata(path, level)
 		if partNum > 1 {
 			metadata["part"] = fmt.Sprintf("%d", partNum)
 			metadata["has_parts"] = "true"
 		}
-		if parentSection != "" && parentSection != section {
-			metadata["parent_section"] = parentSection
-		}
 
-		chunks = append(chunks, CreateChunk(currentPart.String(), source, sectionWithPart, metadata))
+		chunks = append(chunks, CreateChunk(m.withHeadingPath(currentPart.String(), path, partNum > 1), source, sectionWithPart, metadata))
 	}
 
 	return chunks
 }
 
-// extractText извлекает текст из узла AST
-func extractText(node ast.Node, source []byte) string {
-	var buf strings.Builder
-	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
-		if textNode, ok := child.(*ast.Text); ok {
-			buf.Write(textNode.Segment.Value(source))
+// headingMetadata записывает путь заголовков: целиком в heading_path и по уровню в h1..h6
+func headingMetadata(path []headingEntry, level int) map[string]string {
+	metadata := map[string]string{
+		"level": fmt.Sprintf("%d", level),
+	}
+	if len(path) == 0 {
+		return metadata
+	}
+	metadata["heading_path"] = joinHeadingPath(path)
+	for _, h := range path {
+		metadata[fmt.Sprintf("h%d", h.level)] = h.text
+	}
+	if len(path) > 1 {
+		metadata["parent_section"] = path[len(path)-2].text
+	}
+	return metadata
+}
+
+// withHeadingPath добавляет путь заголовков в начало текста, если это включено в конфигурации.
+// Собственный заголовок секции добавляется только если его нет в тексте (includeSelf)
+func (m *MarkdownChunker) withHeadingPath(text string, path []headingEntry, includeSelf bool) string {
+	if !m.config.PrependHeadingPath {
+		return text
+	}
+	if !includeSelf && len(path) > 0 {
+		path = path[:len(path)-1]
+	}
+	if len(path) == 0 {
+		return text
+	}
+	return joinHeadingPath(path) + "\n\n" + strings.TrimSpace(text)
+}
+
+func joinHeadingPath(path []headingEntry) string {
+	parts := make([]string, len(path))
+	for i, h := range path {
+		parts[i] = h.text
+	}
+	return strings.Join(parts, headingPathSeparator)
+}
+
+// fitBlocks делит блоки длиннее MaxChunkSize: многострочные (длинные списки, таблицы) — по строкам,
+// то есть пунктам и рядам, а абзацы и слишком длинные строки — на куски из целых предложений
+func (m *MarkdownChunker) fitBlocks(blocks []string) []string {
+	var result []string
+	for _, block := range blocks {
+		units := []string{block}
+		if m.config.Size(block) > m.config.MaxChunkSize && strings.Contains(block, "\n") {
+			units = strings.Split(block, "\n")
 		}
+		for _, unit := range m.config.fitParagraphs(units, m.config.MaxChunkSize) {
+			if strings.TrimSpace(unit) != "" {
+				result = append(result, unit)
+			}
+		}
+	}
+	return result
+}
+
+// blockUnits возвращает блок как единицу группировки. Список, который не помещается в чанк,
+// делится на пункты — каждый со своим маркером
+func (m *MarkdownChunker) blockUnits(block ast.Node, content []byte) []string {
+	text := renderBlock(block, content)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	list, ok := block.(*ast.List)
+	if !ok || m.config.Size(text) <= m.config.MaxChunkSize {
+		return []string{text}
+	}
+
+	var items []string
+	num := list.Start
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		items = append(items, renderListItem(list, item, num, content))
+		num++
 	}
-	return buf.String()
+	return items
 }
@@ -0,0 +1,278 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// selectStrategyFor разбирает markdown и выбирает стратегию так же, как MarkdownChunker.Chunk
+func selectStrategyFor(t *testing.T, config Config, content string) ChunkingStrategy {
+	t.Helper()
+	m := NewMarkdownChunker(config)
+	doc := parseMarkdown([]byte(content))
+	strategy, err := m.selectStrategy(doc, []byte(content), m.analyzeStructure(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strategy
+}
+
+// sections собирает markdown из заголовков уровня level, у каждого — count абзацев
+func sections(level, n, paragraphs int, paragraph string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%s Статья %d\n\n", strings.Repeat("#", level), i)
+		for p := 0; p < paragraphs; p++ {
+			b.WriteString(paragraph + "\n\n")
+		}
+	}
+	return b.String()
+}
+
+func TestMarkdownSelectStrategy(t *testing.T) {
+	paragraph := "Работодатель обязан обеспечить безопасные условия труда."
+
+	// Три больших раздела H2, в каждом — пять подразделов H3 под размер чанка
+	var nested strings.Builder
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(&nested, "## Раздел %d\n\n", i)
+		for j := 1; j <= 5; j++ {
+			fmt.Fprintf(&nested, "### Статья %d.%d\n\n%s\n\n%s\n\n%s\n\n", i, j, paragraph, paragraph, paragraph)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		content string
+		level   int
+		reason  string
+	}{
+		{"first", Config{MaxChunkSize: 200}, nested.String(), 2, "first level in h2–h4 with ≥3 headings (found 3)"},
+		{"auto", Config{MaxChunkSize: 200, HeadingStrategy: HeadingStrategyAuto}, nested.String(), 3, "lowest section size deviation from max chunk size among h2–h4"},
+		{"h1 per article", Config{MaxChunkSize: 200}, sections(1, 4, 2, paragraph), 1, "falling back to h1 (found 4 ≥ 3)"},
+		{"h1 per article, auto", Config{MaxChunkSize: 200, HeadingStrategy: HeadingStrategyAuto}, sections(1, 4, 2, paragraph), 1, "falling back to h1"},
+		{"h1 in range", Config{MaxChunkSize: 200, HeadingMinLevel: 1}, sections(1, 4, 2, paragraph), 1, "first level in h1–h4"},
+		{"too few headings", Config{MaxChunkSize: 200}, sections(2, 2, 2, paragraph), 0, "no heading level in h2–h4 has enough headings (h2: 2 < 3)"},
+		{"min headings from config", Config{MaxChunkSize: 200, MinHeadings: map[int]int{2: 2}}, sections(2, 2, 2, paragraph), 2, "≥2 headings (found 2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.SizeUnit = SizeRunes
+			strategy := selectStrategyFor(t, tt.config, tt.content)
+			if strategy.Level != tt.level {
+				t.Errorf("level %d, want %d (%s)", strategy.Level, tt.level, strategy.Reason)
+			}
+			if !strings.Contains(strategy.Reason, tt.reason) {
+				t.Errorf("reason %q, want %q", strategy.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMarkdownScoreLevel(t *testing.T) {
+	paragraph := strings.Repeat("я", 90)
+	content := sections(2, 3, 1, paragraph)
+	section := len([]rune("Статья 1\n\n" + paragraph)) // 100 символов
+	m := NewMarkdownChunker(Config{MaxChunkSize: section, SizeUnit: SizeRunes})
+	doc := parseMarkdown([]byte(content))
+
+	score, oversized, n := m.scoreLevel(doc, []byte(content), 2)
+	if n != 3 || oversized != 0 || score != 0 {
+		t.Errorf("h2: score %.3f, %d oversized, %d sections; want 0, 0, 3", score, oversized, n)
+	}
+
+	// Лимит вдвое меньше секций: каждая превышает его на 100%
+	m.config.MaxChunkSize = section / 2
+	score, oversized, _ = m.scoreLevel(doc, []byte(content), 2)
+	if oversized != 3 || score != 1 {
+		t.Errorf("h2 with half limit: score %.3f, %d oversized; want 1 and 3", score, oversized)
+	}
+
+	// Секции вдвое меньше лимита: отклонение -0.5, квадрат 0.25
+	m.config.MaxChunkSize = section * 2
+	if score, oversized, _ = m.scoreLevel(doc, []byte(content), 2); oversized != 0 || score != 0.25 {
+		t.Errorf("h2 with double limit: score %.3f, %d oversized; want 0.25 and 0", score, oversized)
+	}
+}
+
+// Документ со статьёй на каждый H1 при настройках по умолчанию разбивается по статьям, а не по абзацам
+func TestMarkdownChunkerSplitsH1Articles(t *testing.T) {
+	content := sections(1, 4, 2, "Работник имеет право на отдых.")
+	chunks, _, err := NewFactory(Config{MaxChunkSize: 1000, SizeUnit: SizeRunes}).Chunk(content, "doc.md", FormatMarkdown, "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks, want one per H1", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if want := fmt.Sprintf("Статья %d", i+1); chunk.Section != want || chunk.Metadata["method"] != "headings-h1" {
+			t.Errorf("chunk %d: section %q, method %q", i, chunk.Section, chunk.Metadata["method"])
+		}
+	}
+}
+
+// markdownChunks разбивает документ markdown chunker'ом через фабрику и возвращает чанки по секциям
+func markdownChunks(t *testing.T, config Config, content string) map[string]Chunk {
+	t.Helper()
+	config.SizeUnit = SizeRunes
+	chunks, _, err := NewFactory(config).Chunk(content, "codex.md", FormatMarkdown, "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bySection := make(map[string]Chunk, len(chunks))
+	for _, chunk := range chunks {
+		bySection[chunk.Section] = chunk
+	}
+	return bySection
+}
+
+// Чанк хранит полный путь заголовков: heading_path, каждый уровень в h1..h6 и родительский раздел.
+// Заголовок закрывает заголовки своего уровня и глубже, пропущенный уровень не наследуется от соседнего раздела
+func TestMarkdownChunkerHeadingMetadata(t *testing.T) {
+	content := "# Трудовой кодекс\n\n## Раздел I\n\n### Глава 1\n\n" +
+		"#### Статья 1\n\nТекст статьи один.\n\n#### Статья 2\n\nТекст статьи два.\n\n#### Статья 3\n\nТекст статьи три.\n\n" +
+		"### Глава 2\n\n#### Статья 4\n\nТекст статьи четыре.\n\n" +
+		"## Раздел II\n\n#### Статья 5\n\nПервое предложение статьи пять, довольно длинное. Второе предложение статьи пять. " +
+		"Третье предложение статьи пять, которое уже не помещается.\n"
+	config := Config{MaxChunkSize: 120, MinHeadings: map[int]int{4: 3}}
+
+	tests := []struct {
+		section string
+		want    map[string]string // "" — ключа быть не должно
+	}{
+		{"Статья 3", map[string]string{
+			"heading_path": "Трудовой кодекс > Раздел I > Глава 1 > Статья 3", "level": "4",
+			"h1": "Трудовой кодекс", "h2": "Раздел I", "h3": "Глава 1", "h4": "Статья 3", "h5": "",
+			"parent_section": "Глава 1",
+		}},
+		{"Статья 4", map[string]string{
+			"heading_path": "Трудовой кодекс > Раздел I > Глава 2 > Статья 4", "h3": "Глава 2", "h4": "Статья 4",
+			"parent_section": "Глава 2",
+		}},
+		{"Глава 2", map[string]string{
+			"heading_path": "Трудовой кодекс > Раздел I > Глава 2", "level": "3", "h3": "Глава 2", "h4": "", "parent_section": "Раздел I",
+		}},
+		{"Статья 5", map[string]string{
+			"heading_path": "Трудовой кодекс > Раздел II > Статья 5", "h2": "Раздел II", "h3": "", "h4": "Статья 5",
+			"parent_section": "Раздел II", "part": "1",
+		}},
+		{"Статья 5 (часть 2)", map[string]string{
+			"heading_path": "Трудовой кодекс > Раздел II > Статья 5", "parent_section": "Раздел II", "part": "2", "has_parts": "true",
+		}},
+		{"Трудовой кодекс", map[string]string{"heading_path": "Трудовой кодекс", "h1": "Трудовой кодекс", "parent_section": ""}},
+	}
+
+	chunks := markdownChunks(t, config, content)
+	for _, tt := range tests {
+		chunk, ok := chunks[tt.section]
+		if !ok {
+			t.Errorf("no chunk for %s", tt.section)
+			continue
+		}
+		for key, want := range tt.want {
+			if got, ok := chunk.Metadata[key]; got != want || (want == "" && ok) {
+				t.Errorf("%s: %s = %q, want %q", tt.section, key, got, want)
+			}
+		}
+	}
+	if text := chunks["Статья 3"].Text; text != "Статья 3\n\nТекст статьи три." {
+		t.Errorf("text without PrependHeadingPath: %q", text)
+	}
+
+	// С PrependHeadingPath путь предков попадает в текст; у продолжения статьи — вместе с её заголовком
+	config.PrependHeadingPath = true
+	chunks = markdownChunks(t, config, content)
+	for section, prefix := range map[string]string{
+		"Статья 3":           "Трудовой кодекс > Раздел I > Глава 1\n\nСтатья 3\n\nТекст статьи три.",
+		"Статья 5 (часть 2)": "Трудовой кодекс > Раздел II > Статья 5\n\nТретье предложение",
+		"Трудовой кодекс":    "Трудовой кодекс",
+	} {
+		if text := chunks[section].Text; !strings.HasPrefix(text, prefix) {
+			t.Errorf("%s: text %q, want prefix %q", section, text, prefix)
+		}
+	}
+}
+
+// Текст чанка сохраняет структуру markdown: маркеры и нумерацию списков, строки таблиц GFM,
+// код, ссылки с адресом, цитаты; список, не влезающий в чанк, делится по пунктам с их маркерами
+func TestMarkdownChunkerRendersBlocks(t *testing.T) {
+	content := "## Статья 21\n\nРаботник имеет право на:\n\n" +
+		"1) заключение, изменение и расторжение трудового договора;\n" +
+		"2) предоставление ему работы, обусловленной трудовым договором;\n" +
+		"   - в том числе дистанционной;\n   - и надомной;\n" +
+		"3) рабочее место, соответствующее требованиям охраны труда.\n\n" +
+		"## Статья 22\n\n| Вид отпуска | Дней |\n|---|---|\n| Основной | 28 |\n| Дополнительный \\| северный | 24 |\n\n" +
+		"- [x] ознакомлен\n- [ ] подписал\n\n" +
+		"> Цитата `ст. 81` и [ТК РФ](http://tk.ru) ~~старое~~\n\n" +
+		"## Статья 23\n\nРаботодатель обязан:\n\n* соблюдать **законы**;\n\n* выплачивать зарплату.\n"
+
+	tests := []struct {
+		name    string
+		size    int
+		section string
+		want    string
+	}{
+		{"ordered list", 1000, "Статья 21", "Статья 21\n\nРаботник имеет право на:\n\n" +
+			"1) заключение, изменение и расторжение трудового договора;\n" +
+			"2) предоставление ему работы, обусловленной трудовым договором;\n" +
+			"   - в том числе дистанционной;\n   - и надомной;\n" +
+			"3) рабочее место, соответствующее требованиям охраны труда."},
+		{"table, task list and quote", 1000, "Статья 22", "Статья 22\n\n" +
+			"| Вид отпуска | Дней |\n| --- | --- |\n| Основной | 28 |\n| Дополнительный \\| северный | 24 |\n\n" +
+			"- [x] ознакомлен\n- [ ] подписал\n\n" +
+			"> Цитата `ст. 81` и ТК РФ (http://tk.ru) ~~старое~~"},
+		{"loose list", 1000, "Статья 23", "Статья 23\n\nРаботодатель обязан:\n\n- соблюдать законы;\n\n- выплачивать зарплату."},
+		{"long list: last item", 150, "Статья 21 (часть 3)", "3) рабочее место, соответствующее требованиям охраны труда."},
+		{"long list: first item", 150, "Статья 21", "Статья 21\n\nРаботник имеет право на:\n\n1) заключение, изменение и расторжение трудового договора;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk, ok := markdownChunks(t, Config{MaxChunkSize: tt.size}, content)[tt.section]
+			if !ok {
+				t.Fatalf("no chunk for %s", tt.section)
+			}
+			if chunk.Text != tt.want {
+				t.Errorf("text %q\nwant %q", chunk.Text, tt.want)
+			}
+		})
+	}
+
+	// Без подходящих заголовков — разбиение по блокам с тем же рендерингом
+	plain := "Работник имеет право на:\n\n1. заключение трудового договора;\n2. предоставление работы.\n\nИные права — в договоре.\n"
+	for _, chunk := range markdownChunks(t, Config{MaxChunkSize: 1000}, plain) {
+		if want := "Работник имеет право на:\n\n1. заключение трудового договора;\n2. предоставление работы.\n\nИные права — в договоре."; chunk.Text != want {
+			t.Errorf("paragraph chunk %q, want %q", chunk.Text, want)
+		}
+	}
+}
+
+// Front matter не попадает в текст чанков, его поля дополняют метаданные заголовков в каждом чанке,
+// а строки считаются от начала файла вместе с front matter
+func TestMarkdownChunkerFrontMatter(t *testing.T) {
+	content := "---\ntitle: Правила внутреннего трудового распорядка\nedition: 2024-09-01\n---\n\n" +
+		sections(2, 3, 1, "Работник обязан соблюдать трудовую дисциплину.")
+	chunks := markdownChunks(t, Config{MaxChunkSize: 60}, content)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for i := 1; i <= 3; i++ {
+		section := fmt.Sprintf("Статья %d", i)
+		chunk := chunks[section]
+		if strings.Contains(chunk.Text, "title") || strings.Contains(chunk.Text, "---") {
+			t.Errorf("%s: front matter in text %q", section, chunk.Text)
+		}
+		if chunk.Metadata["doc_title"] != "Правила внутреннего трудового распорядка" || chunk.Metadata["doc_edition"] != "2024-09-01" {
+			t.Errorf("%s: front matter metadata %v", section, chunk.Metadata)
+		}
+		if chunk.Metadata["heading_path"] != section || chunk.Metadata["h2"] != section {
+			t.Errorf("%s: heading metadata %v", section, chunk.Metadata)
+		}
+	}
+	if line := chunks["Статья 1"].Metadata["start_line"]; line != "6" {
+		t.Errorf("Статья 1 starts at line %s, want 6", line)
+	}
+}
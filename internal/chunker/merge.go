@@ -0,0 +1,107 @@
+package chunker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MergeSmall присоединяет чанки меньше minSize к соседям: заголовок без текста ("Глава 1. ОСНОВНЫЕ НАЧАЛА...")
+// — к следующему чанку своего раздела, короткий хвост секции — к предыдущему чанку того же раздела или родителю.
+// Если родство по пути заголовков не найдено, чанк присоединяется к следующему (или к последнему, если он в конце).
+// Утратившие силу чанки не сливаются: иначе пометка repealed исказила бы соседа.
+// Сосед, который после слияния превысил бы maxSize (0 — без ограничения), не выбирается. Возвращает чанки и число слияний
+func MergeSmall(chunks []Chunk, minSize, maxSize int, size func(string) int) ([]Chunk, int) {
+	if minSize <= 0 || len(chunks) < 2 {
+		return chunks, 0
+	}
+
+	result := make([]Chunk, 0, len(chunks))
+	merged := 0
+	for i := range chunks {
+		chunk := chunks[i]
+		if size(chunk.Text) >= minSize || isRepealed(chunk) {
+			result = append(result, chunk)
+			continue
+		}
+
+		fits := func(neighbour Chunk) bool {
+			return !isRepealed(neighbour) && (maxSize <= 0 || size(chunk.Text+"\n\n"+neighbour.Text) <= maxSize)
+		}
+		var next, prev *Chunk
+		if i+1 < len(chunks) && fits(chunks[i+1]) {
+			next = &chunks[i+1]
+		}
+		if len(result) > 0 && fits(result[len(result)-1]) {
+			prev = &result[len(result)-1]
+		}
+
+		switch {
+		case next != nil && withinSection(*next, chunk):
+			prependChunk(next, chunk)
+		case prev != nil && withinSection(chunk, *prev):
+			appendChunk(prev, chunk)
+		case next != nil:
+			prependChunk(next, chunk)
+		case prev != nil:
+			appendChunk(prev, chunk)
+		default:
+			result = append(result, chunk)
+			continue
+		}
+		merged++
+	}
+	return result, merged
+}
+
+func isRepealed(chunk Chunk) bool {
+	return chunk.Metadata["repealed"] == "true"
+}
+
+// withinSection проверяет, что chunk лежит в разделе section (тот же путь заголовков или вложенный)
+func withinSection(chunk, section Chunk) bool {
+	inner, outer := chunk.Metadata["heading_path"], section.Metadata["heading_path"]
+	return inner == outer || strings.HasPrefix(inner, outer+headingPathSeparator)
+}
+
+// prependChunk добавляет текст small в начало target
+func prependChunk(target *Chunk, small Chunk) {
+	target.Text = small.Text + "\n\n" + target.Text
+	target.ContentHash = ContentHash(target.Text)
+	mergeChunkPositions(target, small)
+}
+
+// appendChunk добавляет текст small в конец target
+func appendChunk(target *Chunk, small Chunk) {
+	target.Text = target.Text + "\n\n" + small.Text
+	target.ContentHash = ContentHash(target.Text)
+	mergeChunkPositions(target, small)
+}
+
+// mergeChunkPositions расширяет положение target (AnnotatePositions) до положения small
+func mergeChunkPositions(target *Chunk, small Chunk) {
+	if target.Metadata == nil {
+		return
+	}
+	for _, key := range []string{"start_offset", "start_line", "start_page"} {
+		if v, ok := mergedPosition(target.Metadata[key], small.Metadata[key], false); ok {
+			target.Metadata[key] = v
+		}
+	}
+	for _, key := range []string{"end_offset", "end_line", "end_page"} {
+		if v, ok := mergedPosition(target.Metadata[key], small.Metadata[key], true); ok {
+			target.Metadata[key] = v
+		}
+	}
+}
+
+func mergedPosition(a, b string, end bool) (string, bool) {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		return "", false
+	}
+	if end {
+		return strconv.Itoa(max(x, y)), true
+	}
+	return strconv.Itoa(min(x, y)), true
+}
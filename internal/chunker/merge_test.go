@@ -0,0 +1,117 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMergeSmall(t *testing.T) {
+	long := strings.Repeat("Работник обязан соблюдать правила. ", 3)
+	chunk := func(path, text string) Chunk {
+		return Chunk{Text: text, Metadata: map[string]string{"heading_path": path}}
+	}
+	repealed := func(path, text string) Chunk {
+		c := chunk(path, text)
+		c.Metadata["repealed"] = "true"
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		minSize int
+		maxSize int
+		chunks  []Chunk
+		want    []string
+	}{
+		{
+			name:    "disabled",
+			minSize: 0,
+			chunks:  []Chunk{chunk("Глава 1", "Глава 1"), chunk("Глава 1 > Статья 1", long)},
+			want:    []string{"Глава 1", long},
+		},
+		{
+			name:    "heading joins the next chunk of its section",
+			minSize: 20,
+			chunks: []Chunk{
+				chunk("Глава 1", long),
+				chunk("Глава 2", "Глава 2"),
+				chunk("Глава 2 > Статья 5", long),
+			},
+			want: []string{long, "Глава 2\n\n" + long},
+		},
+		{
+			name:    "section tail joins the previous chunk of the same section",
+			minSize: 20,
+			chunks: []Chunk{
+				chunk("Глава 1 > Статья 1", long),
+				chunk("Глава 1 > Статья 1", "Хвост статьи."),
+				chunk("Глава 2 > Статья 2", long),
+			},
+			want: []string{long + "\n\nХвост статьи.", long},
+		},
+		{
+			name:    "without a common section the next chunk is used",
+			minSize: 20,
+			chunks: []Chunk{
+				chunk("Глава 1", long),
+				chunk("Приложение", "Приложение"),
+				chunk("Глава 2", long),
+			},
+			want: []string{long, "Приложение\n\n" + long},
+		},
+		{
+			name:    "last chunk joins the previous one",
+			minSize: 20,
+			chunks:  []Chunk{chunk("Глава 1", long), chunk("Глава 2", "Конец.")},
+			want:    []string{long + "\n\nКонец."},
+		},
+		{
+			name:    "neighbour that would exceed max size is skipped",
+			minSize: 20,
+			maxSize: utf8.RuneCountInString(long) + 10,
+			chunks: []Chunk{
+				chunk("Глава 1 > Статья 1", long),
+				chunk("Глава 1 > Статья 1", "Короткий хвост статьи."),
+				chunk("Глава 2", "Глава 2"),
+			},
+			want: []string{long, "Короткий хвост статьи.\n\nГлава 2"},
+		},
+		{
+			name:    "repealed chunks are not merged",
+			minSize: 20,
+			chunks: []Chunk{
+				repealed("Глава 1 > Статья 1", "Статья 1."),
+				chunk("Глава 1 > Статья 2", "Статья 2. Текст."),
+				repealed("Глава 1 > Статья 3", "Статья 3."),
+			},
+			want: []string{"Статья 1.", "Статья 2. Текст.", "Статья 3."},
+		},
+		{
+			name:    "in-force chunk skips a repealed neighbour",
+			minSize: 20,
+			chunks: []Chunk{
+				chunk("Глава 1 > Статья 1", long),
+				chunk("Глава 1 > Статья 1", "Хвост статьи."),
+				repealed("Глава 1 > Статья 2", "Статья 2."),
+			},
+			want: []string{long + "\n\nХвост статьи.", "Статья 2."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, merged := MergeSmall(tt.chunks, tt.minSize, tt.maxSize, utf8.RuneCountInString)
+			var got []string
+			for _, c := range chunks {
+				got = append(got, c.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q\nwant %q", got, tt.want)
+			}
+			if want := len(tt.chunks) - len(tt.want); merged != want {
+				t.Errorf("merged %d, want %d", merged, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package chunker
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// anchorWords — длина последовательности слов, по которой чанк ищется в исходном тексте
+const anchorWords = 6
+
+// maxAnchorOccurrences — последовательности, встречающиеся в тексте чаще, не участвуют в поиске
+const maxAnchorOccurrences = 20
+
+// PageBreak разделяет страницы в тексте, извлечённом из PDF: reader пишет его перед каждой страницей,
+// кроме первой, в том числе перед пустой, поэтому номер страницы — 1 + число разделителей до позиции
+const PageBreak = "\f"
+
+// wordPos — слово исходного текста (в нижнем регистре, ё→е) и его байтовые границы
+type wordPos struct {
+	word       string
+	start, end int
+}
+
+// anchorVote — найденная в тексте последовательность слов чанка
+type anchorVote struct {
+	offset      int // индекс слова текста, с которого начался бы чанк
+	first, last int // индексы слов последовательности в тексте
+}
+
+// locator находит чанки в исходном тексте. Текст чанка может отличаться от исходного
+// (отрендеренный markdown, добавленный путь заголовков, схлопнутые пробелы), поэтому сравниваются
+// последовательности слов, а не байты
+type locator struct {
+	words      []wordPos
+	grams      map[uint64][]int // hash anchorWords слов → индексы первого слова (по возрастанию)
+	lineStarts []int
+	pageBreaks []int
+}
+
+func newLocator(content string) *locator {
+	l := &locator{
+		words:      splitWords(content),
+		grams:      make(map[uint64][]int),
+		lineStarts: []int{0},
+	}
+	for i := 0; i+anchorWords <= len(l.words); i++ {
+		h := hashWords(wordStrings(l.words[i : i+anchorWords]))
+		l.grams[h] = append(l.grams[h], i)
+	}
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\n':
+			l.lineStarts = append(l.lineStarts, i+1)
+		case PageBreak[0]:
+			l.pageBreaks = append(l.pageBreaks, i)
+		}
+	}
+	return l
+}
+
+// AnnotatePositions записывает в Metadata положение чанков в исходном тексте:
+// start_offset/end_offset (байты), start_line/end_line и, для PDF с разделителями страниц, start_page/end_page.
+// Позиции считаются в тексте, который вернул reader: для .md и .txt это сам файл, для DOCX и HTML —
+// полученный из них Markdown, для PDF — извлечённый текст. Смещения — байты этого текста в UTF-8: у .txt
+// в windows-1251, KOI8-R или UTF-16 они не совпадают с байтами файла, строки — совпадают.
+// Чанки, которые не удалось найти, остаются без позиций
+func AnnotatePositions(content string, chunks []Chunk) {
+	l := newLocator(content)
+	floor := 0
+	for i := range chunks {
+		words := wordStrings(splitWords(chunks[i].Text))
+		first, last, ok := l.find(words, floor)
+		if !ok {
+			first, last, ok = l.find(words, 0)
+		}
+		if !ok {
+			continue
+		}
+		// Чанки идут по порядку документа, но с overlap: следующий ищем не раньше начала текущего
+		floor = first
+
+		start, end := l.words[first].start, l.words[last].end
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = make(map[string]string)
+		}
+		chunks[i].Metadata["start_offset"] = strconv.Itoa(start)
+		chunks[i].Metadata["end_offset"] = strconv.Itoa(end)
+		chunks[i].Metadata["start_line"] = strconv.Itoa(l.line(start))
+		chunks[i].Metadata["end_line"] = strconv.Itoa(l.line(end - 1))
+		if len(l.pageBreaks) > 0 {
+			chunks[i].Metadata["start_page"] = strconv.Itoa(l.page(start))
+			chunks[i].Metadata["end_page"] = strconv.Itoa(l.page(end - 1))
+		}
+	}
+}
+
+// find возвращает индексы первого и последнего слова чанка в исходном тексте, начиная со слова floor
+func (l *locator) find(words []string, floor int) (int, int, bool) {
+	n := len(words)
+	if n == 0 {
+		return 0, 0, false
+	}
+	if n < anchorWords {
+		for p := floor; p+n <= len(l.words); p++ {
+			if l.matches(p, words) {
+				return p, p + n - 1, true
+			}
+		}
+		return 0, 0, false
+	}
+
+	// Каждая последовательность слов чанка, найденная в тексте, голосует за сдвиг чанка относительно текста.
+	// Побеждает самая плотная группа сдвигов: путь заголовков в начале чанка и частые фразы
+	// ("в редакции Федерального закона от ...") дают разрозненные голоса и не сбивают положение
+	var votes []anchorVote
+	for i := 0; i+anchorWords <= n; i++ {
+		positions := l.positions(words[i:i+anchorWords], floor)
+		if len(positions) > maxAnchorOccurrences {
+			continue
+		}
+		for _, p := range positions {
+			votes = append(votes, anchorVote{offset: p - i, first: p, last: p + anchorWords - 1})
+		}
+	}
+	if len(votes) == 0 {
+		return 0, 0, false
+	}
+	sort.Slice(votes, func(a, b int) bool { return votes[a].offset < votes[b].offset })
+
+	// Допуск на слова, которых нет в тексте (адреса ссылок, вырезанные пометки)
+	tolerance := anchorWords + n/10
+	bestFrom, bestTo := 0, 0
+	for from, to := 0, 0; from < len(votes); from++ {
+		for to < len(votes) && votes[to].offset-votes[from].offset <= tolerance {
+			to++
+		}
+		if to-from > bestTo-bestFrom {
+			bestFrom, bestTo = from, to
+		}
+	}
+
+	head, tail := votes[bestFrom], votes[bestFrom]
+	for _, v := range votes[bestFrom:bestTo] {
+		if v.first < head.first {
+			head = v
+		}
+		if v.last > tail.last {
+			tail = v
+		}
+	}
+
+	// Частые фразы голосовать не могли — дотягиваем границы пословным сравнением
+	first, last := head.first, tail.last
+	for i := first - head.offset - 1; i >= 0 && first > 0 && l.words[first-1].word == words[i]; i-- {
+		first--
+	}
+	for i := last - tail.offset + 1; i < n && last+1 < len(l.words) && l.words[last+1].word == words[i]; i++ {
+		last++
+	}
+	return first, last, true
+}
+
+// positions возвращает вхождения последовательности слов не раньше floor
+func (l *locator) positions(words []string, floor int) []int {
+	all := l.grams[hashWords(words)]
+	return all[sort.SearchInts(all, floor):]
+}
+
+// matches проверяет, что слова текста начиная с p совпадают с words
+func (l *locator) matches(p int, words []string) bool {
+	if p+len(words) > len(l.words) {
+		return false
+	}
+	for i, w := range words {
+		if l.words[p+i].word != w {
+			return false
+		}
+	}
+	return true
+}
+
+// line возвращает номер строки (с 1) для байтового смещения
+func (l *locator) line(offset int) int {
+	return sort.SearchInts(l.lineStarts, offset+1)
+}
+
+// page возвращает номер страницы (с 1) для байтового смещения
+func (l *locator) page(offset int) int {
+	return sort.SearchInts(l.pageBreaks, offset) + 1
+}
+
+// splitWords выделяет слова (буквы и цифры) с их байтовыми границами
+func splitWords(text string) []wordPos {
+	var words []wordPos
+	start := -1
+	for i := 0; i <= len(text); {
+		r, size := utf8.RuneError, 1
+		if i < len(text) {
+			r, size = utf8.DecodeRuneInString(text[i:])
+		}
+		isWord := i < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			word := strings.ReplaceAll(strings.ToLower(text[start:i]), "ё", "е")
+			words = append(words, wordPos{word: word, start: start, end: i})
+			start = -1
+		}
+		i += size
+	}
+	return words
+}
+
+func wordStrings(words []wordPos) []string {
+	result := make([]string, len(words))
+	for i, w := range words {
+		result[i] = w.word
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package chunker
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestAnnotatePositions(t *testing.T) {
+	// Статьи без точки в конце: положение — от первого до последнего слова чанка
+	const (
+		article1 = "Статья 1. Работник обязан добросовестно исполнять свои трудовые обязанности"
+		article2 = "Статья 2. Работодатель обязан выплачивать заработную плату в полном размере"
+		article3 = "Статья 3. Ежегодный оплачиваемый отпуск предоставляется работнику каждый год"
+	)
+
+	// span — ожидаемое положение чанка: фрагмент исходного текста между start_offset и end_offset, строки и страницы.
+	// Пустой text — чанк не найден и остаётся без позиций
+	type span struct {
+		text               string
+		startLine, endLine int
+		startPage, endPage int
+	}
+	tests := []struct {
+		name    string
+		content string
+		chunks  []string
+		want    []span
+	}{
+		{
+			name:    "paragraphs",
+			content: article1 + "\n\n" + article2 + "\n" + article3,
+			chunks:  []string{article1, article2 + "\n" + article3},
+			want:    []span{{text: article1, startLine: 1, endLine: 1}, {text: article2 + "\n" + article3, startLine: 3, endLine: 4}},
+		},
+		{
+			// Отрендеренный markdown: путь заголовков в начале чанка, без разметки и двойных пробелов
+			name:    "rendered chunk text",
+			content: "# Глава 1\n\n## Статья 1\n\nРаботник  обязан **добросовестно** исполнять свои трудовые обязанности.\n",
+			chunks:  []string{"Глава 1 > Статья 1\n\nРаботник обязан добросовестно исполнять свои трудовые обязанности."},
+			want:    []span{{text: "Глава 1\n\n## Статья 1\n\nРаботник  обязан **добросовестно** исполнять свои трудовые обязанности", startLine: 1, endLine: 5}},
+		},
+		{
+			name:    "short chunk",
+			content: article1 + ".\n\nПриложение 1\n",
+			chunks:  []string{"Приложение 1"},
+			want:    []span{{text: "Приложение 1", startLine: 3, endLine: 3}},
+		},
+		{
+			// Одинаковый текст в двух местах: следующий чанк ищется после предыдущего
+			name:    "repeated text",
+			content: article1 + "\n\n" + article2 + "\n\n" + article1,
+			chunks:  []string{article1, article2, article1},
+			want: []span{
+				{text: article1, startLine: 1, endLine: 1},
+				{text: article2, startLine: 3, endLine: 3},
+				{text: article1, startLine: 5, endLine: 5},
+			},
+		},
+		{
+			name:    "not found",
+			content: article1,
+			chunks:  []string{"Статья 99. Такой нормы в тексте нет и никогда не было."},
+			want:    []span{{}},
+		},
+		{
+			// Разделитель пишется и перед пустой страницей: третья страница пуста, article3 — на четвёртой
+			name:    "pdf pages",
+			content: article1 + "\n" + PageBreak + article2 + "\n" + PageBreak + PageBreak + article3,
+			chunks:  []string{article1, article2 + " " + article3},
+			want: []span{
+				{text: article1, startLine: 1, endLine: 1, startPage: 1, endPage: 1},
+				{text: article2 + "\n" + PageBreak + PageBreak + article3, startLine: 2, endLine: 3, startPage: 2, endPage: 4},
+			},
+		},
+		{
+			// Смещения — байты UTF-8: кириллическая буква занимает два байта
+			name:    "utf-8 offsets",
+			content: "Ёлка. " + article2,
+			chunks:  []string{article2},
+			want:    []span{{text: article2, startLine: 1, endLine: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := make([]Chunk, len(tt.chunks))
+			for i, text := range tt.chunks {
+				chunks[i] = Chunk{Text: text}
+			}
+			AnnotatePositions(tt.content, chunks)
+
+			for i, want := range tt.want {
+				m := chunks[i].Metadata
+				if want.text == "" {
+					if _, ok := m["start_offset"]; ok {
+						t.Errorf("chunk %d: unexpected position %v", i, m)
+					}
+					continue
+				}
+				start, _ := strconv.Atoi(m["start_offset"])
+				end, _ := strconv.Atoi(m["end_offset"])
+				if got := tt.content[start:end]; got != want.text {
+					t.Errorf("chunk %d: offsets %d–%d cover %q, want %q", i, start, end, got, want.text)
+				}
+				if got := m["start_line"] + "–" + m["end_line"]; got != strconv.Itoa(want.startLine)+"–"+strconv.Itoa(want.endLine) {
+					t.Errorf("chunk %d: lines %s, want %d–%d", i, got, want.startLine, want.endLine)
+				}
+				if want.startPage == 0 {
+					if _, ok := m["start_page"]; ok {
+						t.Errorf("chunk %d: pages without page breaks: %v", i, m)
+					}
+					continue
+				}
+				if got := m["start_page"] + "–" + m["end_page"]; got != strconv.Itoa(want.startPage)+"–"+strconv.Itoa(want.endPage) {
+					t.Errorf("chunk %d: pages %s, want %d–%d", i, got, want.startPage, want.endPage)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package chunker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Форматы текста, которые reader документа сообщает фабрике вместе с текстом: .docx и HTML
+// читаются в Markdown, поэтому для них подходит markdown chunker, хотя расширение другое.
+// FormatLegal — Markdown, в котором заголовки нормативного акта (ЧАСТЬ, Раздел, Глава, Статья) расставил
+// сам reader: их уровни не совпадают с уровнями, которые выбирает markdown chunker
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+	FormatLegal    = "legal"
+)
+
+// Registration описывает chunker в реестре фабрики
+type Registration struct {
+	Name       string   // Основное имя (значение CHUNK_METHOD)
+	Aliases    []string // Дополнительные имена метода
+	Extensions []string // Расширения файлов (".md"), для которых chunker выбирается по умолчанию
+	Formats    []string // Форматы текста от reader'а документа (FormatMarkdown), для которых chunker выбирается по умолчанию
+
+	// Sniff — необязательная проверка содержимого для CHUNK_METHOD=auto.
+	// Проверяется раньше расширения файла: распознанная структура важнее формата
+	Sniff func(content string) bool
+
+	// New создаёт chunker с общей конфигурацией
+	New func(config Config) (Chunker, error)
+}
+
+var (
+	registryMu    sync.RWMutex
+	registrations []Registration
+)
+
+// Register добавляет chunker в реестр. Обычно вызывается из init() файла chunker'а.
+// Повторная регистрация имени или алиаса — ошибка программиста, поэтому panic
+func Register(r Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if r.Name == "" || r.New == nil {
+		panic("chunker: Register requires Name and New")
+	}
+	for _, name := range append([]string{r.Name}, r.Aliases...) {
+		if _, ok := lookupLocked(name); ok {
+			panic(fmt.Sprintf("chunker: %q registered twice", name))
+		}
+	}
+	registrations = append(registrations, r)
+}
+
+// Registered возвращает имена зарегистрированных chunker'ов
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registrations))
+	for _, r := range registrations {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup находит регистрацию по имени или алиасу
+func lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return lookupLocked(name)
+}
+
+func lookupLocked(name string) (Registration, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, r := range registrations {
+		if r.Name == name {
+			return r, true
+		}
+		for _, alias := range r.Aliases {
+			if alias == name {
+				return r, true
+			}
+		}
+	}
+	return Registration{}, false
+}
+
+// sniff возвращает первый chunker, распознавший содержимое
+func sniff(content string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, r := range registrations {
+		if r.Sniff != nil && r.Sniff(content) {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+// byExtension находит chunker по расширению файла (".md")
+func byExtension(ext string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	ext = strings.ToLower(ext)
+	for _, r := range registrations {
+		for _, e := range r.Extensions {
+			if e == ext {
+				return r, true
+			}
+		}
+	}
+	return Registration{}, false
+}
+
+// byFormat находит chunker по формату текста от reader'а (FormatMarkdown)
+func byFormat(format string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, r := range registrations {
+		for _, f := range r.Formats {
+			if f == format {
+				return r, true
+			}
+		}
+	}
+	return Registration{}, false
+}
@@ -0,0 +1,165 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// renderBlock восстанавливает текст блока markdown с сохранением структуры:
+// маркеры и нумерация списков, строки таблиц, код, цитаты. Заголовки выводятся без "#" —
+// так же, как в заголовках чанков
+func renderBlock(n ast.Node, source []byte) string {
+	switch node := n.(type) {
+	case *ast.Heading:
+		return renderInline(node, source)
+	case *ast.Paragraph, *ast.TextBlock:
+		return renderInline(node, source)
+	case *ast.List:
+		return renderList(node, source)
+	case *ast.Blockquote:
+		return prefixLines(renderChildren(node, source, "\n\n"), "> ")
+	case *ast.FencedCodeBlock:
+		return "```" + string(node.Language(source)) + "\n" + blockLines(node, source) + "```"
+	case *ast.CodeBlock:
+		return "```\n" + blockLines(node, source) + "```"
+	case *ast.HTMLBlock:
+		return strings.TrimSpace(blockLines(node, source))
+	case *ast.ThematicBreak:
+		return "---"
+	case *east.Table:
+		return renderTable(node, source)
+	default:
+		if n.Type() == ast.TypeInline {
+			return renderInline(n, source)
+		}
+		return renderChildren(n, source, "\n\n")
+	}
+}
+
+// renderChildren рендерит дочерние блоки через разделитель
+func renderChildren(n ast.Node, source []byte, sep string) string {
+	var parts []string
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if text := renderBlock(child, source); strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// renderList выводит каждый пункт с его маркером: "1) ...", "2. ...", "- ..."
+func renderList(list *ast.List, source []byte) string {
+	var items []string
+	num := list.Start
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		items = append(items, renderListItem(list, item, num, source))
+		num++
+	}
+	sep := "\n"
+	if !list.IsTight {
+		sep = "\n\n"
+	}
+	return strings.Join(items, sep)
+}
+
+// renderListItem выводит пункт списка с маркером; строки продолжения сдвигаются под текст пункта
+func renderListItem(list *ast.List, item ast.Node, num int, source []byte) string {
+	marker := "- "
+	if list.IsOrdered() {
+		marker = fmt.Sprintf("%d%c ", num, list.Marker)
+	}
+	sep := "\n"
+	if !list.IsTight {
+		sep = "\n\n"
+	}
+	body := renderChildren(item, source, sep)
+	indent := strings.Repeat(" ", len(marker))
+	return marker + strings.ReplaceAll(body, "\n", "\n"+indent)
+}
+
+// renderTable выводит таблицу GFM построчно
+func renderTable(table *east.Table, source []byte) string {
+	var lines []string
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			// "\|" в исходной ячейке goldmark оставляет в тексте как есть: экранируется только голый "|"
+			text := strings.ReplaceAll(renderInline(cell, source), "\n", " ")
+			text = strings.ReplaceAll(text, `\|`, "|")
+			cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if _, ok := row.(*east.TableHeader); ok {
+			lines = append(lines, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline собирает текст inline-узлов: ссылки с адресом, код в обратных кавычках, переносы строк
+func renderInline(n ast.Node, source []byte) string {
+	var buf strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch node := child.(type) {
+		case *ast.Text:
+			buf.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				buf.WriteString("\n")
+			}
+		case *ast.String:
+			buf.Write(node.Value)
+		case *ast.CodeSpan:
+			buf.WriteString("`" + renderInline(node, source) + "`")
+		case *ast.Link:
+			text := renderInline(node, source)
+			buf.WriteString(text)
+			if dest := string(node.Destination); dest != "" && dest != text {
+				buf.WriteString(" (" + dest + ")")
+			}
+		case *ast.Image:
+			buf.WriteString(renderInline(node, source))
+		case *ast.AutoLink:
+			buf.Write(node.URL(source))
+		case *ast.RawHTML:
+			for i := 0; i < node.Segments.Len(); i++ {
+				segment := node.Segments.At(i)
+				buf.Write(segment.Value(source))
+			}
+		case *east.Strikethrough:
+			buf.WriteString("~~" + renderInline(node, source) + "~~")
+		case *east.TaskCheckBox:
+			if node.IsChecked {
+				buf.WriteString("[x] ")
+			} else {
+				buf.WriteString("[ ] ")
+			}
+		default:
+			// Emphasis и прочие контейнеры — только текст
+			buf.WriteString(renderInline(node, source))
+		}
+	}
+	return buf.String()
+}
+
+// blockLines возвращает строки блока как есть (код, HTML)
+func blockLines(n ast.Node, source []byte) string {
+	var buf strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		buf.Write(line.Value(source))
+	}
+	return buf.String()
+}
+
+// prefixLines добавляет префикс к каждой строке
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,207 @@
+package chunker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EmbeddingFunc возвращает вектор для текста (совместима с chromem.EmbeddingFunc)
+type EmbeddingFunc func(ctx context.Context, text string) ([]float32, error)
+
+// semanticAutoPercentile — перцентиль сходства соседних предложений, ниже которого режем чанк,
+// если порог не задан явно
+const semanticAutoPercentile = 0.2
+
+func init() {
+	Register(Registration{
+		Name: "semantic",
+		New:  func(config Config) (Chunker, error) { return NewSemanticChunker(config) },
+	})
+}
+
+// SemanticChunker режет текст там, где соседние предложения перестают быть похожими по смыслу
+type SemanticChunker struct {
+	config Config
+}
+
+// NewSemanticChunker создаёт новый semantic chunker. Требует Config.Embed
+func NewSemanticChunker(config Config) (*SemanticChunker, error) {
+	if config.Embed == nil {
+		return nil, fmt.Errorf("semantic chunker requires an embedding function")
+	}
+	return &SemanticChunker{config: config}, nil
+}
+
+func (s *SemanticChunker) Name() string {
+	return "semantic"
+}
+
+func (s *SemanticChunker) Chunk(content, source string) ([]Chunk, error) {
+	// Предложение длиннее MaxChunkSize стало бы отдельным сверхбольшим чанком — режется заранее
+	sentences := s.config.splitLongSentences(SplitSentences(content), s.config.MaxChunkSize)
+	if len(sentences) == 0 {
+		return nil, fmt.Errorf("semantic chunker found no sentences")
+	}
+
+	log.Printf("📊 [%s] Embedding %d sentences...", s.Name(), len(sentences))
+	embeddings, err := s.embedAll(context.Background(), sentences)
+	if err != nil {
+		return nil, fmt.Errorf("semantic chunker cannot embed sentences: %w", err)
+	}
+
+	// similarities[i] — сходство предложений i и i+1
+	similarities := make([]float64, len(sentences)-1)
+	for i := range similarities {
+		similarities[i] = cosineSimilarity(embeddings[i], embeddings[i+1])
+	}
+
+	threshold := float64(s.config.SemanticThreshold)
+	if threshold <= 0 {
+		threshold = percentile(similarities, semanticAutoPercentile)
+		log.Printf("🎯 [%s] Auto threshold: %.3f (p%.0f of neighbour similarity)", s.Name(), threshold, semanticAutoPercentile*100)
+	} else {
+		log.Printf("🎯 [%s] Threshold: %.3f", s.Name(), threshold)
+	}
+
+	var chunks []Chunk
+	var current []string
+	chunkNum := 1
+	boundary := ""
+	boundarySimilarity := 0.0
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		metadata := map[string]string{
+			"chunk_num": fmt.Sprintf("%d", chunkNum),
+			"method":    "semantic",
+		}
+		if boundary != "" {
+			metadata["boundary"] = boundary
+			metadata["boundary_similarity"] = fmt.Sprintf("%.3f", boundarySimilarity)
+		}
+		chunks = append(chunks, CreateChunk(strings.Join(current, " "), source,
+			fmt.Sprintf("Чанк %d", chunkNum), metadata))
+		current = nil
+		chunkNum++
+	}
+
+	for i, sentence := range sentences {
+		if len(current) > 0 {
+			sim := similarities[i-1]
+			switch {
+			case sim < threshold:
+				flush()
+				boundary, boundarySimilarity = "similarity", sim
+			case s.config.Size(strings.Join(current, " ")+" "+sentence) > s.config.MaxChunkSize:
+				flush()
+				boundary, boundarySimilarity = "size", sim
+			}
+		}
+		current = append(current, sentence)
+	}
+	flush()
+
+	log.Printf("✅ [%s] Created %d chunks", s.Name(), len(chunks))
+	return chunks, nil
+}
+
+// embedAll векторизует предложения с ограничением параллельности; одинаковые предложения — один запрос
+func (s *SemanticChunker) embedAll(ctx context.Context, sentences []string) ([][]float32, error) {
+	concurrency := s.config.EmbedConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Горутины пишут только в свой элемент vectors: map не меняется, пока её обходят
+	index := make(map[string]int)
+	var texts []string
+	for _, sentence := range sentences {
+		if _, ok := index[sentence]; !ok {
+			index[sentence] = len(texts)
+			texts = append(texts, sentence)
+		}
+	}
+	vectors := make([][]float32, len(texts))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	// Слот занимается до запуска горутины: на документ из тысяч предложений работает не больше concurrency горутин
+	for i, text := range texts {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, text string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			vec, err := s.config.Embed(ctx, text)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			vectors[i] = vec
+		}(i, text)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	result := make([][]float32, len(sentences))
+	for i, sentence := range sentences {
+		result[i] = vectors[index[sentence]]
+	}
+	return result, nil
+}
+
+// cosineSimilarity считает косинусное сходство векторов
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// percentile возвращает p-й перцентиль значений (p от 0 до 1)
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted[int(p*float64(len(sorted)-1))]
+}
@@ -0,0 +1,117 @@
+package chunker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Векторизация идёт параллельно: тест имеет смысл под go test -race
+func TestSemanticChunkerSplitsOnTopicChange(t *testing.T) {
+	vacation := "Работнику предоставляется ежегодный оплачиваемый отпуск."
+	salary := "Заработная плата выплачивается не реже двух раз в месяц."
+	content := strings.Repeat(vacation+" ", 3) + strings.Repeat(salary+" ", 3)
+
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int)
+	)
+	embed := func(ctx context.Context, text string) ([]float32, error) {
+		mu.Lock()
+		calls[text]++
+		mu.Unlock()
+		if strings.Contains(text, "отпуск") {
+			return []float32{1, 0}, nil
+		}
+		return []float32{0, 1}, nil
+	}
+
+	config := Config{MaxChunkSize: 1000, SizeUnit: SizeRunes, Embed: embed, EmbedConcurrency: 4, SemanticThreshold: 0.5}
+	chunker, err := NewSemanticChunker(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := chunker.Chunk(content, "doc.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	if strings.Contains(chunks[0].Text, "Заработная") || strings.Contains(chunks[1].Text, "отпуск") {
+		t.Errorf("topics mixed: %q | %q", chunks[0].Text, chunks[1].Text)
+	}
+	if chunks[1].Metadata["boundary"] != "similarity" {
+		t.Errorf("boundary %q, want similarity", chunks[1].Metadata["boundary"])
+	}
+	for text, n := range calls {
+		if n != 1 {
+			t.Errorf("%q embedded %d times, want once", text, n)
+		}
+	}
+}
+
+// Горутин векторизации не больше EmbedConcurrency, сколько бы предложений ни было в документе;
+// после первой ошибки новые запросы не отправляются
+func TestSemanticChunkerEmbedConcurrency(t *testing.T) {
+	const concurrency = 2
+	var b strings.Builder
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&b, "Работник номер %d обязан соблюдать правила внутреннего распорядка. ", i)
+	}
+	content := b.String()
+
+	baseline := runtime.NumGoroutine()
+	var (
+		calls, inFlight, maxInFlight, maxGoroutines atomic.Int64
+		failAt                                      atomic.Int64
+	)
+	embed := func(ctx context.Context, text string) ([]float32, error) {
+		n := calls.Add(1)
+		if cur := inFlight.Add(1); cur > maxInFlight.Load() {
+			maxInFlight.Store(cur)
+		}
+		defer inFlight.Add(-1)
+		if g := int64(runtime.NumGoroutine()); g > maxGoroutines.Load() {
+			maxGoroutines.Store(g)
+		}
+		if at := failAt.Load(); at > 0 && n >= at {
+			return nil, errors.New("embedding API unavailable")
+		}
+		return []float32{1, 0}, nil
+	}
+
+	chunker, err := NewSemanticChunker(Config{MaxChunkSize: 100000, SizeUnit: SizeRunes, Embed: embed, EmbedConcurrency: concurrency, SemanticThreshold: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := chunker.Chunk(content, "doc.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 200 {
+		t.Errorf("embedded %d sentences, want 200", calls.Load())
+	}
+	if maxInFlight.Load() > concurrency {
+		t.Errorf("%d requests in flight, want at most %d", maxInFlight.Load(), concurrency)
+	}
+	// Запас на горутины рантайма и тестов; горутина на каждое предложение дала бы ~200
+	if extra := maxGoroutines.Load() - int64(baseline); extra > concurrency+10 {
+		t.Errorf("%d extra goroutines while embedding, want about %d", extra, concurrency)
+	}
+
+	calls.Store(0)
+	failAt.Store(10)
+	if _, err := chunker.Chunk(content, "doc.txt"); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Fatalf("error %v, want the embedding error", err)
+	}
+	if n := calls.Load(); n > 10+concurrency {
+		t.Errorf("%d requests sent after the first error", n-10)
+	}
+}
@@ -0,0 +1,226 @@
+package chunker
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span — фрагмент исходного текста (байтовые смещения)
+type Span struct {
+	Start int
+	End   int
+}
+
+// Сокращения, после которых точка не заканчивает предложение (без точки, в нижнем регистре)
+var sentenceAbbreviations = map[string]bool{
+	"ст": true, "ч": true, "п": true, "пп": true, "подп": true, "абз": true, "гл": true,
+	"разд": true, "прил": true, "см": true, "ср": true, "напр": true, "т.е": true, "т.к": true,
+	"т.н": true, "и.о": true, "т.ч": true, "им": true, "обл": true, "р-н": true,
+	"ул": true, "д": true, "кв": true, "стр": true, "корп": true, "тел": true, "рис": true,
+	"табл": true, "no": true, "№": true, "проф": true, "доц": true, "акад": true, "зам": true,
+	"нач": true, "пред": true, "ред": true, "изд": true, "вып": true,
+}
+
+// Сокращения, которые могут стоять в конце предложения: граница, если дальше заглавная буква
+var sentenceFinalAbbreviations = map[string]bool{
+	"т.д": true, "т.п": true, "др": true, "пр": true, "вв": true,
+	"руб": true, "коп": true, "тыс": true, "млн": true, "млрд": true, "мин": true, "сек": true,
+	"шт": true, "экз": true,
+}
+
+// Сокращения "год" и "годы": в конце предложения стоят только после года ("в 2020 г."),
+// а перед названием ("г. Москва") не заканчивают его
+var yearAbbreviations = map[string]bool{"г": true, "гг": true}
+
+// Маркер пункта перечня в начале строки: "1)", "1.", "1.2.", "а)", "-", "•"
+var reListMarker = regexp.MustCompile(`^(\d+(?:\.\d+)*[.)]|[а-яёa-z]\)|[-–—•·*])[\s\x{00A0}]`)
+
+// SegmentSentences находит границы предложений с учётом русских юридических сокращений
+// ("ст.", "ч.", "п.", "т.д.", "г.", "руб.") и маркеров перечней. Пустые строки и начало
+// пункта перечня с новой строки всегда считаются границей
+func SegmentSentences(text string) []Span {
+	var spans []Span
+	start := 0
+
+	emit := func(end int) {
+		s, e := trimSpan(text, start, end)
+		if s < e {
+			spans = append(spans, Span{Start: s, End: e})
+		}
+		start = end
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case r == '\n':
+			// Пустая строка — граница абзаца
+			next := skipSpaces(text, i+size, true)
+			if next < len(text) && text[next] == '\n' {
+				emit(i)
+				i = next
+				continue
+			}
+			// Новая строка, начинающаяся с маркера перечня — новое предложение
+			if reListMarker.MatchString(text[next:]) {
+				emit(i)
+			}
+		case r == '.' || r == '!' || r == '?' || r == '…':
+			end := i + size
+			// Многоточия, "?!", закрывающие кавычки и скобки относятся к текущему предложению
+			for end < len(text) {
+				nr, ns := utf8.DecodeRuneInString(text[end:])
+				if !strings.ContainsRune(".!?…\"»)]", nr) {
+					break
+				}
+				end += ns
+			}
+			next := skipSpaces(text, end, false)
+			if next == end || next >= len(text) {
+				// Нет пробела после знака (1.2.3, т.д.) или конец текста
+				i = end
+				continue
+			}
+			if r == '.' && !isSentenceEnd(text, start, i, next) {
+				i = end
+				continue
+			}
+			if r != '.' && !startsSentence(text[next:]) {
+				i = end
+				continue
+			}
+			emit(end)
+			i = end
+			continue
+		}
+		i += size
+	}
+	emit(len(text))
+
+	return spans
+}
+
+// SplitSentences разбивает текст на предложения, схлопывая пробелы и переносы внутри них
+func SplitSentences(text string) []string {
+	spans := SegmentSentences(text)
+	sentences := make([]string, 0, len(spans))
+	for _, span := range spans {
+		sentences = append(sentences, strings.Join(strings.Fields(text[span.Start:span.End]), " "))
+	}
+	return sentences
+}
+
+// isSentenceEnd решает, заканчивает ли точка в позиции dot предложение, начатое в start
+func isSentenceEnd(text string, start, dot, next int) bool {
+	word := wordBefore(text, dot)
+	lower := strings.ToLower(word)
+
+	// Маркер перечня в начале предложения ("1.", "2.3.") — не конец предложения
+	if isDigits(strings.ReplaceAll(word, ".", "")) && strings.TrimSpace(text[start:dot-len(word)]) == "" {
+		return false
+	}
+	// Инициалы: "И. И. Иванов"
+	if utf8.RuneCountInString(word) == 1 && unicode.IsUpper([]rune(word)[0]) {
+		return false
+	}
+	if sentenceAbbreviations[lower] {
+		return false
+	}
+	if yearAbbreviations[lower] && !isYear(wordBefore(text, skipSpacesBack(text, dot-len(word)))) {
+		return false
+	}
+	if sentenceFinalAbbreviations[lower] || yearAbbreviations[lower] {
+		// "в 2001 г. Работник ..." — граница только перед заглавной буквой
+		r, _ := utf8.DecodeRuneInString(text[next:])
+		return unicode.IsUpper(r)
+	}
+	return startsSentence(text[next:])
+}
+
+// startsSentence проверяет, может ли с этого места начинаться предложение
+func startsSentence(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	if unicode.IsLower(r) {
+		return false
+	}
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune("\"«(—–-•", r)
+}
+
+// wordBefore возвращает слово (с внутренними точками) перед позицией pos
+func wordBefore(text string, pos int) string {
+	begin := pos
+	for begin > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:begin])
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '№') {
+			break
+		}
+		begin -= size
+	}
+	return strings.Trim(text[begin:pos], ".")
+}
+
+// skipSpaces пропускает пробельные символы; при inline не переходит на следующую строку
+func skipSpaces(text string, pos int, inline bool) int {
+	for pos < len(text) {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if !unicode.IsSpace(r) || inline && r == '\n' {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+// skipSpacesBack возвращает позицию перед пробельными символами, которые заканчиваются в pos
+func skipSpacesBack(text string, pos int) int {
+	for pos > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:pos])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		pos -= size
+	}
+	return pos
+}
+
+// trimSpan сужает фрагмент до непробельных символов
+func trimSpan(text string, start, end int) (int, int) {
+	for start < end {
+		r, size := utf8.DecodeRuneInString(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		start += size
+	}
+	for end > start {
+		r, size := utf8.DecodeLastRuneInString(text[start:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	return start, end
+}
+
+// isYear проверяет, что слово — год из четырёх цифр ("2020", "2019-2020")
+func isYear(word string) bool {
+	if i := strings.LastIndex(word, "-"); i >= 0 {
+		word = word[i+1:]
+	}
+	return len(word) == 4 && isDigits(word)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package chunker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSentencesAbbreviations(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "article reference",
+			text: "Работник уволен по ст. 81 Трудового кодекса. Решение обжаловано.",
+			want: []string{"Работник уволен по ст. 81 Трудового кодекса.", "Решение обжаловано."},
+		},
+		{
+			name: "city",
+			text: "Работник проживает в г. Москве и работает удалённо.",
+			want: []string{"Работник проживает в г. Москве и работает удалённо."},
+		},
+		{
+			name: "year at the end of a sentence",
+			text: "Договор заключён в 2020 г. Работник приступил к работе.",
+			want: []string{"Договор заключён в 2020 г.", "Работник приступил к работе."},
+		},
+		{
+			name: "years range",
+			text: "Отпуск за 2019-2020 гг. Предоставлен полностью.",
+			want: []string{"Отпуск за 2019-2020 гг.", "Предоставлен полностью."},
+		},
+		{
+			name: "etc",
+			text: "Работник получает премии, надбавки и т.д. Размер выплат определяет работодатель.",
+			want: []string{"Работник получает премии, надбавки и т.д.", "Размер выплат определяет работодатель."},
+		},
+		{
+			name: "etc inside a sentence",
+			text: "Премии, надбавки и т.д. выплачиваются ежемесячно.",
+			want: []string{"Премии, надбавки и т.д. выплачиваются ежемесячно."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitSentences(%q)\n got: %q\nwant: %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package chunker
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	tiktoken "github.com/pkoukk/tiktoken-go"
+)
+
+// SizeUnit — единица измерения MaxChunkSize и Overlap
+type SizeUnit string
+
+const (
+	SizeBytes  SizeUnit = "bytes"  // байты UTF-8 (кириллица — 2 байта на букву)
+	SizeRunes  SizeUnit = "runes"  // символы Unicode
+	SizeTokens SizeUnit = "tokens" // токены cl100k_base, как у embedding/LLM моделей
+)
+
+// ParseSizeUnit разбирает название единицы измерения. Пустая строка означает символы.
+// Токены требуют encoder cl100k_base: если он недоступен (нет сети для загрузки словаря), возвращается ошибка,
+// а не молчаливая оценка по байтам
+func ParseSizeUnit(s string) (SizeUnit, error) {
+	switch SizeUnit(strings.ToLower(strings.TrimSpace(s))) {
+	case "", SizeRunes, "chars", "symbols":
+		return SizeRunes, nil
+	case SizeBytes:
+		return SizeBytes, nil
+	case SizeTokens:
+		if err := loadTokenizer(); err != nil {
+			return "", fmt.Errorf("chunk size unit tokens is unavailable: %w", err)
+		}
+		return SizeTokens, nil
+	default:
+		return "", fmt.Errorf("unknown chunk size unit: %s (supported: bytes, runes, tokens)", s)
+	}
+}
+
+// Кэшированный encoder для подсчёта токенов
+var (
+	tiktokenOnce sync.Once
+	tiktokenEnc  *tiktoken.Tiktoken
+	tiktokenErr  error
+	tiktokenWarn sync.Once
+)
+
+// loadTokenizer загружает encoder cl100k_base один раз и возвращает ошибку загрузки
+func loadTokenizer() error {
+	tiktokenOnce.Do(func() {
+		tiktokenEnc, tiktokenErr = tiktoken.GetEncoding("cl100k_base")
+	})
+	return tiktokenErr
+}
+
+// CountTokens точно подсчитывает количество токенов в тексте
+// Использует cl100k_base encoding (для GPT-4, Qwen и совместимых моделей)
+func CountTokens(text string) int {
+	if err := loadTokenizer(); err != nil {
+		tiktokenWarn.Do(func() {
+			log.Printf("⚠️  Tokenizer cl100k_base is unavailable, token counts are estimated as bytes/2: %v", err)
+		})
+		// Fallback на консервативную оценку для русского текста
+		return len(text) / 2
+	}
+	tokens := tiktokenEnc.Encode(text, nil, nil)
+	return len(tokens)
+}
+
+// Size измеряет текст в единицах, заданных в конфигурации
+func (c Config) Size(text string) int {
+	switch c.SizeUnit {
+	case SizeBytes:
+		return len(text)
+	case SizeTokens:
+		return CountTokens(text)
+	default:
+		return utf8.RuneCountInString(text)
+	}
+}
+
+// fitPrefix возвращает максимальное число рун с начала, которое укладывается в limit (минимум одна руна)
+func (c Config) fitPrefix(runes []rune, limit int) int {
+	if len(runes) == 0 {
+		return 0
+	}
+	if c.Size(string(runes)) <= limit {
+		return len(runes)
+	}
+	// Размер монотонно растёт с длиной — ищем границу бинарным поиском
+	lo, hi := 1, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if c.Size(string(runes[:mid])) <= limit {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// splitLongSentences режет предложения длиннее limit по границам слов
+func (c Config) splitLongSentences(sentences []string, limit int) []string {
+	var result []string
+	for _, sentence := range sentences {
+		for c.Size(sentence) > limit {
+			runes := []rune(sentence)
+			cut := c.fitPrefix(runes, limit)
+			if space := strings.LastIndexFunc(string(runes[:cut]), unicode.IsSpace); space > 0 {
+				cut = utf8.RuneCountInString(string(runes[:cut])[:space])
+			}
+			result = append(result, strings.TrimSpace(string(runes[:cut])))
+			sentence = strings.TrimSpace(string(runes[cut:]))
+		}
+		if sentence != "" {
+			result = append(result, sentence)
+		}
+	}
+	return result
+}
+
+// fitParagraphs делит абзацы длиннее limit на куски из целых предложений (packSentences)
+func (c Config) fitParagraphs(paragraphs []string, limit int) []string {
+	result := make([]string, 0, len(paragraphs))
+	for _, para := range paragraphs {
+		if c.Size(para) <= limit {
+			result = append(result, para)
+			continue
+		}
+		result = append(result, c.packSentences(SplitSentences(para), limit)...)
+	}
+	return result
+}
+
+// packSentences собирает предложения в куски не больше limit; предложения длиннее limit режутся по словам
+func (c Config) packSentences(sentences []string, limit int) []string {
+	var pieces []string
+	var current string
+	for _, sentence := range c.splitLongSentences(sentences, limit) {
+		if current != "" && c.Size(current+" "+sentence) > limit {
+			pieces = append(pieces, current)
+			current = ""
+		}
+		if current != "" {
+			current += " "
+		}
+		current += sentence
+	}
+	if current != "" {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
@@ -0,0 +1,55 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Абзац длиннее MaxChunkSize не должен попадать в чанк целиком ни в одном из путей разбиения
+func TestChunksFitMaxChunkSize(t *testing.T) {
+	sentence := "Работодатель обязан ознакомить работника с локальными нормативными актами под подпись. "
+	long := strings.TrimSpace(strings.Repeat(sentence, 12))
+
+	var list strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&list, "- Пункт %d: %s\n", i, strings.TrimSpace(sentence))
+	}
+
+	var withHeadings strings.Builder
+	for i := 1; i <= 4; i++ {
+		fmt.Fprintf(&withHeadings, "## Раздел %d\n\n%s\n\n%s\n\n%s\n\n", i, sentence, long, list.String())
+	}
+	plain := strings.Join([]string{sentence, long, sentence, long, long}, "\n\n")
+
+	tests := []struct {
+		name    string
+		content string
+		file    string
+		method  string
+	}{
+		{"markdown headings", withHeadings.String(), "doc.md", "markdown"},
+		{"markdown paragraphs", plain, "doc.md", "markdown"},
+		{"simple paragraphs", plain, "doc.txt", "simple"},
+	}
+
+	for _, tt := range tests {
+		for _, unit := range []SizeUnit{SizeRunes, SizeBytes} {
+			t.Run(fmt.Sprintf("%s/%s", tt.name, unit), func(t *testing.T) {
+				config := Config{MaxChunkSize: 400, Overlap: 150, MinChunkSize: 64, SizeUnit: unit}
+				chunks, _, err := NewFactory(config).Chunk(tt.content, tt.file, "", tt.method)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(chunks) < 2 {
+					t.Fatalf("got %d chunks, want the text split", len(chunks))
+				}
+				for _, chunk := range chunks {
+					if size := config.Size(chunk.Text); size > config.MaxChunkSize {
+						t.Errorf("%s: %d %s, limit %d", chunk.Section, size, unit, config.MaxChunkSize)
+					}
+				}
+			})
+		}
+	}
+}
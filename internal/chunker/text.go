@@ -6,6 +6,16 @@ import (
 	"strings"
 )
 
+func init() {
+	Register(Registration{
+		Name:       "simple",
+		Aliases:    []string{"text", "txt"},
+		Extensions: []string{".txt", ".text"},
+		Formats:    []string{FormatText},
+		New:        func(config Config) (Chunker, error) { return NewTextChunker(config), nil },
+	})
+}
+
 // TextChunker разбивает plain text по размеру с overlap
 type TextChunker struct {
 	config Config
@@ -35,9 +45,10 @@ func (s *TextChunker) Chunk(content, source string) ([]Chunk, error) {
 	return chunks, nil
 }
 
-// chunkByParagraphs разбивает текст по параграфам с overlap
+// chunkByParagraphs разбивает текст по параграфам с overlap. Параграф длиннее MaxChunkSize
+// делится на куски из целых предложений
 func (s *TextChunker) chunkByParagraphs(content, source string) []Chunk {
-	paragraphs := SplitByParagraphs(content)
+	paragraphs := s.config.fitParagraphs(SplitByParagraphs(content), s.config.MaxChunkSize)
 	var chunks []Chunk
 	var currentChunk strings.Builder
 	chunkNum := 1
@@ -45,7 +56,7 @@ func (s *TextChunker) chunkByParagraphs(content, source string) []Chunk {
 
 	for _, para := range paragraphs {
 		// Если добавление параграфа превысит лимит
-		if currentChunk.Len() > 0 && currentChunk.Len()+len(para) > s.config.MaxChunkSize {
+		if currentChunk.Len() > 0 && s.config.Size(currentChunk.String()+"\n\n"+para) > s.config.MaxChunkSize {
 			chunkText := currentChunk.String()
 			section := fmt.Sprintf("Чанк %d", chunkNum)
 
@@ -54,9 +65,12 @@ func (s *TextChunker) chunkByParagraphs(content, source string) []Chunk {
 				"method":    "paragraphs",
 			}))
 
-			// Сохраняем хвост для overlap
+			// Сохраняем хвост для overlap — целыми предложениями, если рядом с параграфом он помещается в чанк
 			if s.config.Overlap > 0 {
-				prevTail = GetLastNChars(chunkText, s.config.Overlap)
+				prevTail = strings.Join(s.overlapSentences(SplitSentences(chunkText)), " ")
+				if s.config.Size(prevTail+"\n\n"+para) > s.config.MaxChunkSize {
+					prevTail = ""
+				}
 			}
 
 			currentChunk.Reset()
@@ -88,32 +102,54 @@ func (s *TextChunker) chunkByParagraphs(content, source string) []Chunk {
 	return chunks
 }
 
-// chunkBySize простое разбиение по размеру с overlap
+// chunkBySize разбиение по размеру с overlap: чанки собираются из целых предложений
 func (s *TextChunker) chunkBySize(content, source string) []Chunk {
 	var chunks []Chunk
-	runes := []rune(content)
+	var current []string
 	chunkNum := 1
 
-	for i := 0; i < len(runes); i += s.config.MaxChunkSize - s.config.Overlap {
-		end := i + s.config.MaxChunkSize
-		if end > len(runes) {
-			end = len(runes)
+	for _, sentence := range s.config.splitLongSentences(SplitSentences(content), s.config.MaxChunkSize) {
+		if len(current) > 0 && s.config.Size(strings.Join(append(current, sentence), " ")) > s.config.MaxChunkSize {
+			section := fmt.Sprintf("Чанк %d", chunkNum)
+			chunks = append(chunks, CreateChunk(strings.Join(current, " "), source, section, map[string]string{
+				"chunk_num": fmt.Sprintf("%d", chunkNum),
+				"method":    "size",
+			}))
+			chunkNum++
+
+			// Следующий чанк начинается с последних предложений текущего, если они помещаются
+			current = s.overlapSentences(current)
+			if len(current) > 0 && s.config.Size(strings.Join(append(current, sentence), " ")) > s.config.MaxChunkSize {
+				current = nil
+			}
 		}
+		current = append(current, sentence)
+	}
 
-		chunkText := string(runes[i:end])
+	if len(current) > 0 {
 		section := fmt.Sprintf("Чанк %d", chunkNum)
-
-		chunks = append(chunks, CreateChunk(chunkText, source, section, map[string]string{
+		chunks = append(chunks, CreateChunk(strings.Join(current, " "), source, section, map[string]string{
 			"chunk_num": fmt.Sprintf("%d", chunkNum),
 			"method":    "size",
 		}))
+	}
 
-		chunkNum++
+	return chunks
+}
 
-		if end >= len(runes) {
+// overlapSentences выбирает последние целые предложения общим размером не больше Overlap.
+// Все предложения не возвращаются никогда — иначе следующий чанк повторит текущий
+func (s *TextChunker) overlapSentences(sentences []string) []string {
+	if s.config.Overlap <= 0 {
+		return nil
+	}
+	var result []string
+	for i := len(sentences) - 1; i > 0; i-- {
+		candidate := append([]string{sentences[i]}, result...)
+		if s.config.Size(strings.Join(candidate, " ")) > s.config.Overlap {
 			break
 		}
+		result = candidate
 	}
-
-	return chunks
+	return result
 }
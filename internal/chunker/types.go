@@ -2,11 +2,12 @@ package chunker
 
 // Chunk представляет единицу текста для векторизации
 type Chunk struct {
-	ID       string            // Уникальный идентификатор (hash)
-	Text     string            // Текст чанка
-	Source   string            // Имя исходного файла
-	Section  string            // Название секции (заголовок, глава и т.д.)
-	Metadata map[string]string // Дополнительные метаданные
+	ID          string            // Стабильный идентификатор: hash от источника, пути в структуре и порядкового номера
+	ContentHash string            // Hash текста: меняется при любой правке чанка
+	Text        string            // Текст чанка
+	Source      string            // Имя исходного файла
+	Section     string            // Название секции (заголовок, глава и т.д.)
+	Metadata    map[string]string // Дополнительные метаданные
 }
 
 // Chunker - интерфейс для всех типов chunker'ов
@@ -20,6 +21,32 @@ type Chunker interface {
 
 // Config содержит общие параметры для chunker'ов
 type Config struct {
-	MaxChunkSize int // Максимальный размер чанка в символах
-	Overlap      int // Размер overlap между чанками
+	MaxChunkSize int      // Максимальный размер чанка в единицах SizeUnit
+	Overlap      int      // Размер overlap между чанками в единицах SizeUnit
+	MinChunkSize int      // Чанки меньше этого размера сливаются с соседними (0 — не сливать)
+	SizeUnit     SizeUnit // Единица измерения размеров: bytes, runes (по умолчанию) или tokens
+
+	// PrependHeadingPath добавляет путь заголовков ("Раздел I > Глава 1 > Статья 3") в начало текста чанка,
+	// чтобы он попадал в embedding
+	PrependHeadingPath bool
+
+	// ExtractAmendments выносит пометки "(В редакции Федерального закона ...)" из текста в метаданные
+	ExtractAmendments bool
+
+	// Normalize нормализует текст готового чанка; label подписывает debug-сообщения. nil — без нормализации
+	Normalize func(text, label string) string
+
+	// Fallback — chunker'ы, которые пробуются по очереди, если выбранный не справился
+	Fallback []string
+
+	// Параметры выбора стратегии markdown chunker'а. Нулевые значения — H2–H4, пороги 3/5/10, first
+	HeadingMinLevel int         // Первый уровень заголовков, рассматриваемый для разбиения
+	HeadingMaxLevel int         // Последний уровень заголовков, рассматриваемый для разбиения
+	MinHeadings     map[int]int // Минимальное число заголовков уровня, чтобы по нему разбивать
+	HeadingStrategy string      // first — первый подходящий уровень, auto — уровень с наименьшим разбросом размеров секций
+
+	// Параметры semantic chunker'а
+	Embed             EmbeddingFunc // Функция векторизации предложений
+	EmbedConcurrency  int           // Параллельность запросов к embedding API
+	SemanticThreshold float32       // Порог сходства соседних предложений; 0 — автоматический
 }
@@ -3,27 +3,80 @@ package chunker
 import (
 	"crypto/sha256"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
-// CreateChunk создаёт чанк с автоматической генерацией ID
+// CreateChunk создаёт чанк с автоматической генерацией ID.
+// Нормализация текста (пробелы, переносы, кавычки) выполняется позже — Config.Normalize в Factory.Chunk
 func CreateChunk(text, source, section string, metadata map[string]string) Chunk {
 	text = strings.TrimSpace(text)
-	text = cleanChunk(text)
-
-	hash := sha256.Sum256([]byte(text + source))
 
 	if metadata == nil {
 		metadata = make(map[string]string)
 	}
 
+	// Предварительный ID по содержимому; Factory.Chunk заменяет его стабильным AssignIDs
+	contentHash := ContentHash(text)
 	return Chunk{
-		ID:       fmt.Sprintf("%x", hash[:8]),
-		Text:     text,
-		Source:   source,
-		Section:  section,
-		Metadata: metadata,
+		ID:          contentHash,
+		ContentHash: contentHash,
+		Text:        text,
+		Source:      source,
+		Section:     section,
+		Metadata:    metadata,
+	}
+}
+
+// ContentHash возвращает hash текста чанка
+func ContentHash(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return fmt.Sprintf("%x", hash[:8])
+}
+
+// reNumberedSection — секции без структуры ("Чанк 3"): номер зависит только от положения чанка в файле
+var reNumberedSection = regexp.MustCompile(`^Чанк \d+`)
+
+// AssignIDs назначает чанкам стабильные ID: hash от источника, структурного пути (heading_path или секция)
+// и порядкового номера чанка внутри этого пути. Правка текста не меняет ID, одинаковые абзацы не конфликтуют,
+// а вставка чанка затрагивает только ID своего раздела.
+// У чанков без структуры ("Чанк N") путь — это hash нормализованного первого предложения: иначе вставка
+// абзаца в начало файла сдвинула бы ID всех последующих чанков
+func AssignIDs(chunks []Chunk) {
+	ordinals := make(map[string]int)
+	for i := range chunks {
+		chunk := &chunks[i]
+		path := chunk.Metadata["heading_path"]
+		switch {
+		case path != "":
+		case reNumberedSection.MatchString(chunk.Section):
+			path = "\x01" + ContentHash(firstSentence(chunk.Text))
+		default:
+			path = chunk.Section
+		}
+		key := chunk.Source + "\x00" + path
+		ordinal := ordinals[key]
+		ordinals[key]++
+
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, ordinal)))
+		chunk.ID = fmt.Sprintf("%x", hash[:8])
+
+		if chunk.ContentHash == "" {
+			chunk.ContentHash = ContentHash(chunk.Text)
+		}
+		if chunk.Metadata == nil {
+			chunk.Metadata = make(map[string]string)
+		}
+		chunk.Metadata["content_hash"] = chunk.ContentHash
+	}
+}
+
+// firstSentence возвращает первое предложение текста в нижнем регистре с нормализованными пробелами
+func firstSentence(text string) string {
+	if sentences := SplitSentences(text); len(sentences) > 0 {
+		return strings.ToLower(sentences[0])
 	}
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
 }
 
 // GetLastNChars возвращает последние N символов строки для overlap
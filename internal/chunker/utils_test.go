@@ -0,0 +1,84 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+// ID чанка не меняется при повторном разбиении, правке его текста и вставке раздела выше,
+// а одинаковые абзацы одного документа получают разные ID
+func TestAssignIDs(t *testing.T) {
+	factory := NewFactory(Config{MaxChunkSize: 120, SizeUnit: SizeRunes})
+	chunkIDs := func(t *testing.T, content, method string) ([]string, []Chunk) {
+		t.Helper()
+		chunks, _, err := factory.Chunk(content, "rules.md", FormatMarkdown, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(chunks))
+		for i, c := range chunks {
+			ids[i] = c.ID
+		}
+		return ids, chunks
+	}
+	section := func(title, text string) string { return "## " + title + "\n\n" + text + "\n\n" }
+
+	vacation := "Работнику предоставляется ежегодный оплачиваемый отпуск продолжительностью 28 календарных дней."
+	salary := "Заработная плата выплачивается не реже чем каждые полмесяца."
+	hours := "Нормальная продолжительность рабочего времени не может превышать сорока часов в неделю."
+	original := section("Отпуск", vacation) + section("Оплата", salary) + section("Время", hours)
+
+	ids, chunks := chunkIDs(t, original, "markdown")
+	if len(ids) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(ids))
+	}
+
+	t.Run("re-chunking", func(t *testing.T) {
+		again, _ := chunkIDs(t, original, "markdown")
+		if strings.Join(again, ",") != strings.Join(ids, ",") {
+			t.Errorf("ids changed: %v → %v", ids, again)
+		}
+	})
+
+	t.Run("edited text", func(t *testing.T) {
+		got, edited := chunkIDs(t, strings.Replace(original, "полмесяца", "две недели", 1), "markdown")
+		if strings.Join(got, ",") != strings.Join(ids, ",") {
+			t.Errorf("ids changed: %v → %v", ids, got)
+		}
+		if edited[1].ContentHash == chunks[1].ContentHash {
+			t.Error("content hash did not follow the edit")
+		}
+	})
+
+	t.Run("section inserted above", func(t *testing.T) {
+		got, _ := chunkIDs(t, section("Общие положения", "Правила обязательны для всех работников.")+original, "markdown")
+		if len(got) != 4 || strings.Join(got[1:], ",") != strings.Join(ids, ",") {
+			t.Errorf("ids %v, want %v after the new section", got, ids)
+		}
+	})
+
+	t.Run("duplicate paragraphs", func(t *testing.T) {
+		repeated := "Работник обязан соблюдать требования по охране труда и обеспечению безопасности труда."
+		content := section("Права", repeated+"\n\n"+repeated) + section("Обязанности", repeated) + section("Время", hours)
+		got, chunks := chunkIDs(t, content, "markdown")
+		seen := make(map[string]bool)
+		for i, id := range got {
+			if seen[id] {
+				t.Errorf("chunk %d (%s) has a duplicate id %s", i, chunks[i].Metadata["heading_path"], id)
+			}
+			seen[id] = true
+		}
+		if len(got) != 4 {
+			t.Errorf("got %d chunks, want 4", len(got))
+		}
+	})
+
+	t.Run("paragraph inserted into unstructured text", func(t *testing.T) {
+		plain := vacation + "\n\n" + salary + "\n\n" + hours
+		before, _ := chunkIDs(t, plain, "simple")
+		after, _ := chunkIDs(t, "Настоящие правила утверждены приказом директора.\n\n"+plain, "simple")
+		if len(before) != 3 || len(after) != 4 || strings.Join(after[1:], ",") != strings.Join(before, ",") {
+			t.Errorf("ids %v, want %v after the new paragraph", after, before)
+		}
+	})
+}
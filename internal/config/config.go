@@ -20,21 +20,72 @@ type Promt struct {
 	Chunk  string `env:"CHUNK"`
 }
 
+// Chunking — параметры разбиения на чанки. Выделены отдельно, чтобы команда chunk
+// могла работать без настроек LLM
+type Chunking struct {
+	ChunkMethod   string   `env:"CHUNK_METHOD" envDefault:"markdown"` // имя chunker'а или auto
+	ChunkSize     int      `env:"CHUNK_SIZE" envDefault:"1000"`
+	ChunkOverlap  int      `env:"CHUNK_OVERLAP" envDefault:"200"`
+	ChunkMinSize  int      `env:"CHUNK_MIN_SIZE" envDefault:"0"`                       // чанки меньше сливаются с соседними (0 — не сливать)
+	ChunkUnit     string   `env:"CHUNK_SIZE_UNIT" envDefault:"runes"`                  // bytes, runes или tokens
+	ChunkPath     bool     `env:"CHUNK_PREPEND_PATH" envDefault:"false"`               // добавлять путь заголовков в текст чанка
+	ChunkFallback []string `env:"CHUNK_FALLBACK" envSeparator:"," envDefault:"simple"` // цепочка запасных chunker'ов
+
+	// Выбор уровня заголовков для CHUNK_METHOD=markdown: диапазон уровней, минимальное число заголовков
+	// уровня ("уровень:число" через запятую) и стратегия: first — первый подходящий уровень,
+	// auto — уровень, секции которого по размеру ближе всего к CHUNK_SIZE
+	MarkdownMinLevel    int         `env:"MD_HEADING_MIN_LEVEL" envDefault:"2"`
+	MarkdownMaxLevel    int         `env:"MD_HEADING_MAX_LEVEL" envDefault:"4"`
+	MarkdownMinHeadings map[int]int `env:"MD_MIN_HEADINGS" envDefault:"2:3,3:5,4:10"`
+	MarkdownStrategy    string      `env:"MD_STRATEGY" envDefault:"first"`
+
+	// Кодировка .txt и .md: auto (BOM и содержимое), utf-8, windows-1251, koi8-r, ibm866, utf-16le, utf-16be
+	TextEncoding string `env:"TEXT_ENCODING" envDefault:"auto"`
+
+	// Внешний конвертер для прочих форматов (.doc, .rtf, .odt): команда печатает текст в stdout,
+	// {input} — путь к файлу (без него путь добавляется в конец). Без списка расширений вызывается
+	// для всех файлов, которые не распознал ни один встроенный reader. Формат вывода: markdown или text
+	ReaderCommand           string   `env:"READER_COMMAND"`
+	ReaderCommandExtensions []string `env:"READER_COMMAND_EXTENSIONS" envSeparator:","`
+	ReaderCommandFormat     string   `env:"READER_COMMAND_FORMAT" envDefault:"markdown"`
+
+	// Порог сходства соседних предложений для CHUNK_METHOD=semantic (0 — автоматический)
+	SemanticThreshold float32 `env:"SEMANTIC_THRESHOLD" envDefault:"0"`
+
+	// Выносить пометки "(В редакции Федерального закона ...)" из текста чанков в метаданные
+	ExtractAmendments bool `env:"EXTRACT_AMENDMENTS" envDefault:"true"`
+
+	// Шаги нормализации текста для эталонного и проверяемого документа (по порядку, через запятую):
+	// whitespace, edition-notes, hyphenation, quotes, dashes, yo, nbsp, leading-fragment
+	NormalizeReference []string `env:"NORMALIZE_REFERENCE" envSeparator:"," envDefault:"whitespace"`
+	NormalizeCheck     []string `env:"NORMALIZE_CHECK" envSeparator:"," envDefault:"whitespace"`
+
+	// Порог сходства (Jaccard по shingles) для слияния почти одинаковых чанков при индексации (0 — отключено)
+	DedupThreshold float64 `env:"DEDUP_THRESHOLD" envDefault:"0"`
+}
+
 type Config struct {
 	ReferenceDoc string `env:"REFERENCE_DOC"`
 	CheckDoc     string `env:"CHECK_DOC"`
 	DataDir      string `env:"DATA_DIR" envDefault:"./data"`
 	LlmMain      Llm    `envPrefix:"LLM_MAIN_"`
 	LlmEmbed     Llm    `envPrefix:"LLM_EMBED_"`
-	ChunkMethod  string `env:"CHUNK_METHOD" envDefault:"markdown"`
-	ChunkSize    int    `env:"CHUNK_SIZE" envDefault:"1000"`
-	ChunkOverlap int    `env:"CHUNK_OVERLAP" envDefault:"200"`
 	CustomPromt  Promt  `envPrefix:"CUSTOM_PROMPT_"`
 	RunChunker   bool   `env:"RUN_CHUNKER" envDefault:"false"`
 
+	// Параметры chunking
+	Chunking
+
 	// Параметры векторного поиска
 	TopK          int     `env:"TOP_K" envDefault:"5"`
 	MinSimilarity float32 `env:"MIN_SIMILARITY" envDefault:"0.6"`
+	// Parent–child: ищем по мелким дочерним чанкам (предложения, пункты), в LLM отдаём родительский чанк целиком
+	ParentChild bool `env:"PARENT_CHILD" envDefault:"false"`
+	// Индекс построен с другой embedding-моделью или другими параметрами chunking: true — перестроить,
+	// false — остановиться с ошибкой
+	IndexRebuild bool `env:"INDEX_REBUILD" envDefault:"true"`
+	// Не возвращать статьи, утратившие силу (нужен EXTRACT_AMENDMENTS при индексации)
+	ExcludeRepealed bool `env:"EXCLUDE_REPEALED" envDefault:"true"`
 
 	// Параметры LLM (оптимизировано для gemma3)
 	MaxTokens   int     `env:"MAX_TOKENS" envDefault:"2000"`
@@ -72,3 +123,8 @@ func Init(cfg *Config) error {
 
 	return nil
 }
+
+// InitChunking загружает только параметры chunking — для команд, которым не нужны LLM
+func InitChunking(cfg *Config) error {
+	return env.Parse(&cfg.Chunking)
+}
@@ -0,0 +1,257 @@
+// Package normalize — настраиваемая цепочка нормализации текста перед индексацией и анализом
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Version — версия правил нормализации. Увеличивается при изменении поведения шагов:
+// индексы, построенные по прежним правилам, перестраиваются (см. манифест индекса)
+const Version = 2
+
+// Step — именованный шаг нормализации
+type Step struct {
+	Name        string
+	Description string
+	Apply       func(text string) string
+}
+
+// Logf — функция логирования
+type Logf func(format string, args ...interface{})
+
+// snippetContext — сколько символов вокруг изменения показывает debug-сообщение
+const snippetContext = 30
+
+// Pipeline применяет шаги по порядку
+type Pipeline struct {
+	steps  []Step
+	logf   Logf // сводка по шагам (LogSummary)
+	debugf Logf // каждое изменение текста (Apply)
+
+	mu    sync.Mutex
+	stats []stepStats // по шагу на элемент steps, с последнего LogSummary
+}
+
+// stepStats — сколько текстов шаг изменил и насколько они сократились
+type stepStats struct {
+	texts   int
+	changed int
+	removed int // байт; отрицательное — текст удлинился
+}
+
+var (
+	reMultipleNewlines = regexp.MustCompile(`\n{3,}`)
+	reEmptyLines       = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+	reHyphenation      = regexp.MustCompile(`(\p{L})[-\x{00AD}][ \t]*\r?\n[ \t]*(\p{Ll})`)
+	reSpacedDash       = regexp.MustCompile(`[ \t][-\x{2010}\x{2011}\x{2012}\x{2013}\x{2014}\x{2015}\x{2212}][ \t]`)
+)
+
+// EditionNote — пометка об изменении нормы: "(В редакции Федерального закона от ...)", "(Часть утратила силу - ...)",
+// "(Дополнение абзацем - Федеральный закон от ...)", "(Пункт 3 исключен - Указ ... № ...)". До ключевого слова
+// допускается до трёх слов ("Статья", "Часть первая"), после — обязательна ссылка на акт: номер или дата.
+// Пометка может быть обрезана концом чанка. Один шаблон на шаг edition-notes и chunker.ExtractAmendments,
+// чтобы пометка не оставалась в тексте при одном способе обработки и не удалялась при другом
+var EditionNote = regexp.MustCompile(`(?i)[ \t]*\((?:[а-яё\d]+\.?[\s\x{00A0}]+){0,3}?(?:в[\s\x{00A0}]+редакции|дополнен|утратил|признан|исключен[аоы]?[\s\x{00A0}])[^()\n]*(?:№|от[\s\x{00A0}]+\d{1,2}\.\d{2}\.\d{4})[^()\n]*(?:\)|$)`)
+
+// steps — все доступные шаги
+var steps = map[string]Step{
+	"whitespace": {
+		Name:        "whitespace",
+		Description: "схлопывает пробелы внутри строк и пустые строки, обрезает края",
+		Apply:       collapseWhitespace,
+	},
+	"edition-notes": {
+		Name:        "edition-notes",
+		Description: `удаляет пометки "(В редакции Федерального закона ...)", "(Утратил силу - ...)" и опустевшие строки`,
+		Apply: func(text string) string {
+			text = EditionNote.ReplaceAllString(text, "")
+			text = reEmptyLines.ReplaceAllString(text, "\n\n")
+			return strings.TrimSpace(text)
+		},
+	},
+	"hyphenation": {
+		Name:        "hyphenation",
+		Description: "склеивает слова, перенесённые через дефис на следующую строку, убирает мягкие переносы",
+		Apply: func(text string) string {
+			text = reHyphenation.ReplaceAllString(text, "$1$2")
+			return strings.ReplaceAll(text, "\u00AD", "")
+		},
+	},
+	"quotes": {
+		Name:        "quotes",
+		Description: `приводит «ёлочки», „лапки“ и типографские кавычки к "прямым"`,
+		Apply: strings.NewReplacer(
+			"«", `"`, "»", `"`, "„", `"`, "“", `"`, "”", `"`, "‟", `"`,
+			"‘", "'", "’", "'", "‚", "'", "‛", "'",
+		).Replace,
+	},
+	"dashes": {
+		Name:        "dashes",
+		Description: "тире между пробелами → «—», прочие дефисы и минусы → «-»",
+		Apply: func(text string) string {
+			text = reSpacedDash.ReplaceAllStringFunc(text, func(s string) string {
+				first, _ := utf8.DecodeRuneInString(s)
+				last, _ := utf8.DecodeLastRuneInString(s)
+				return string(first) + "—" + string(last)
+			})
+			return strings.NewReplacer(
+				"‐", "-", "‑", "-", "‒", "-", "–", "-", "―", "-", "−", "-",
+			).Replace(text)
+		},
+	},
+	"yo": {
+		Name:        "yo",
+		Description: "заменяет ё на е",
+		Apply:       strings.NewReplacer("ё", "е", "Ё", "Е").Replace,
+	},
+	"nbsp": {
+		Name:        "nbsp",
+		Description: "неразрывные и узкие пробелы → обычный пробел",
+		Apply:       strings.NewReplacer("\u00A0", " ", "\u202F", " ", "\u2007", " ", "\u2009", " ").Replace,
+	},
+	"leading-fragment": {
+		Name:        "leading-fragment",
+		Description: "удаляет первое слово, если текст начинается со строчной буквы (огрызок после резки по размеру)",
+		Apply:       dropLeadingFragment,
+	},
+}
+
+// New собирает pipeline из имён шагов в заданном порядке. logf получает сводку LogSummary,
+// debugf — каждое изменение текста; любая из функций может быть nil
+func New(names []string, logf, debugf Logf) (*Pipeline, error) {
+	p := &Pipeline{logf: logf, debugf: debugf}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		step, ok := steps[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown normalization step: %s (available: %s)", name, strings.Join(Available(), ", "))
+		}
+		p.steps = append(p.steps, step)
+	}
+	p.stats = make([]stepStats, len(p.steps))
+	return p, nil
+}
+
+// Available возвращает имена доступных шагов
+func Available() []string {
+	names := make([]string, 0, len(steps))
+	for name := range steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Names возвращает имена шагов pipeline
+func (p *Pipeline) Names() []string {
+	names := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+// Apply прогоняет текст через все шаги. Каждое изменение пишется в debugf с label (секция, ID чанка)
+// и фрагментом текста до и после шага
+func (p *Pipeline) Apply(text, label string) string {
+	if p == nil {
+		return text
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, step := range p.steps {
+		changed := step.Apply(text)
+		p.stats[i].texts++
+		if changed != text {
+			p.stats[i].changed++
+			p.stats[i].removed += len(text) - len(changed)
+			if p.debugf != nil {
+				before, after := diffSnippet(text, changed)
+				p.debugf("✏️  normalize %s [%s]: %q → %q", step.Name, label, before, after)
+			}
+		}
+		text = changed
+	}
+	return text
+}
+
+// LogSummary пишет по строке на каждый шаг, изменивший текст после прошлого вызова, и обнуляет счётчики
+func (p *Pipeline) LogSummary() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, step := range p.steps {
+		if st := p.stats[i]; st.changed > 0 && p.logf != nil {
+			p.logf("✏️  normalize %s: %d of %d texts changed, %d bytes removed", step.Name, st.changed, st.texts, st.removed)
+		}
+		p.stats[i] = stepStats{}
+	}
+}
+
+// diffSnippet вырезает из текстов до и после шага изменившуюся часть с snippetContext символами вокруг
+func diffSnippet(before, after string) (string, string) {
+	a, b := []rune(before), []rune(after)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	start := max(prefix-snippetContext, 0)
+	cut := func(r []rune) string {
+		end := min(len(r)-suffix+snippetContext, len(r))
+		s := string(r[start:end])
+		if start > 0 {
+			s = "…" + s
+		}
+		if end < len(r) {
+			s += "…"
+		}
+		return s
+	}
+	return cut(a), cut(b)
+}
+
+// collapseWhitespace схлопывает пробелы внутри строк (переносы сохраняются) и множественные пустые строки
+func collapseWhitespace(text string) string {
+	text = strings.TrimSpace(text)
+
+	// Убрать обрезанные слова в конце
+	text = strings.TrimRight(text, " -")
+
+	// Заменить множественные переносы на двойной
+	text = reMultipleNewlines.ReplaceAllString(text, "\n\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dropLeadingFragment — прежнее поведение cleanChunk: строчная буква в начале считалась обрезанным словом.
+// Для перечней в нормативных актах ("трудоустройству у данного работодателя;") это ошибка, поэтому шаг не включён по умолчанию
+func dropLeadingFragment(text string) string {
+	r, _ := utf8.DecodeRuneInString(text)
+	if !unicode.IsLower(r) {
+		return text
+	}
+	parts := strings.SplitN(strings.TrimSpace(text), " ", 2)
+	if len(parts) < 2 {
+		return text
+	}
+	return strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,97 @@
+package normalize
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		step string
+		in   string
+		want string
+	}{
+		{"whitespace", "  Статья  1.\tОбщие   положения \n\n\n\nТекст -", "Статья 1. Общие положения\n\nТекст"},
+		{"edition-notes", "Работник обязан: (В редакции Федерального закона от 30.06.2006 № 90-ФЗ)\n\n(Часть утратила силу - Федеральный закон от 01.01.2020 № 1-ФЗ)\n\nСоблюдать дисциплину", "Работник обязан:\n\nСоблюдать дисциплину"},
+		{"edition-notes", "(в том числе в редакции работодателя)", "(в том числе в редакции работодателя)"},
+		{"hyphenation", "трудо-\nвой до\u00ADговор", "трудовой договор"},
+		{"hyphenation", "Северо-\nЗападный", "Северо-\nЗападный"},
+		{"quotes", "«Трудовой кодекс» и „закон“", `"Трудовой кодекс" и "закон"`},
+		{"dashes", "работник – сторона, 1–2 дня", "работник — сторона, 1-2 дня"},
+		{"yo", "Ёлка ещё", "Елка еще"},
+		{"nbsp", "ст.\u00A081\u202Fч.", "ст. 81 ч."},
+		{"leading-fragment", "ния договора. Работник", "договора. Работник"},
+		{"leading-fragment", "Работник обязан", "Работник обязан"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.step, func(t *testing.T) {
+			p, err := New([]string{tt.step}, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Apply(tt.in, "test"); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	p, err := New([]string{" Quotes ", "", "yo"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(p.Names(), ","); got != "quotes,yo" {
+		t.Errorf("Names() = %s", got)
+	}
+
+	if _, err := New([]string{"whitespace", "typo"}, nil, nil); err == nil {
+		t.Error("expected an error for an unknown step")
+	}
+
+	var nilPipeline *Pipeline
+	if got := nilPipeline.Apply("ё", "test"); got != "ё" {
+		t.Errorf("nil pipeline changed text: %q", got)
+	}
+}
+
+// Каждое изменение пишется в debug-лог с подписью и фрагментом текста, сводка — одна строка на шаг
+func TestPipelineLogging(t *testing.T) {
+	var debug, summary []string
+	p, err := New([]string{"yo", "quotes"},
+		func(format string, args ...interface{}) { summary = append(summary, fmt.Sprintf(format, args...)) },
+		func(format string, args ...interface{}) { debug = append(debug, fmt.Sprintf(format, args...)) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("Работник обязан соблюдать правила. ", 5)
+	p.Apply(long+"Всё «верно»", "Статья 21")
+	p.Apply("Без изменений", "Статья 22")
+
+	if len(debug) != 2 {
+		t.Fatalf("got %d debug lines, want one per changing step: %q", len(debug), debug)
+	}
+	for _, line := range debug {
+		if !strings.Contains(line, "[Статья 21]") {
+			t.Errorf("no label in %q", line)
+		}
+		if strings.Contains(line, long) {
+			t.Errorf("whole text in %q, want a snippet", line)
+		}
+	}
+	if !strings.Contains(debug[0], `"…`) || !strings.Contains(debug[0], `Всё «верно»"`) || !strings.Contains(debug[0], `Все «верно»"`) {
+		t.Errorf("yo change not shown: %q", debug[0])
+	}
+
+	p.LogSummary()
+	if len(summary) != 2 || !strings.Contains(summary[0], "yo: 1 of 2") {
+		t.Errorf("summary %q", summary)
+	}
+	p.LogSummary()
+	if len(summary) != 2 {
+		t.Errorf("summary not reset: %q", summary)
+	}
+}